@@ -1,7 +1,6 @@
 package squirrel
 
 import (
-	"bytes"
 	"errors"
 
 	"github.com/lann/builder"
@@ -14,32 +13,18 @@ func init() {
 // sqlizerBuffer is a helper that allows to write many Sqlizers one by one
 // without constant checks for errors that may come from Sqlizer
 type sqlizerBuffer struct {
-	bytes.Buffer
-	args []interface{}
-	err  error
+	*SqlWriter
+	err error
 }
 
-// WriteSql converts Sqlizer to SQL strings and writes it to buffer
+// WriteSql renders Sqlizer into the writer, followed by a space
 func (b *sqlizerBuffer) WriteSql(item Sqlizer) {
 	if b.err != nil {
 		return
 	}
 
-	var str string
-	var args []interface{}
-	str, args, b.err = nestedToSql(item)
-
-	if b.err != nil {
-		return
-	}
-
-	b.WriteString(str)
-	b.WriteByte(' ')
-	b.args = append(b.args, args...)
-}
-
-func (b *sqlizerBuffer) ToSql() (string, []interface{}, error) {
-	return b.String(), b.args, b.err
+	b.err = b.WriteSqlizer(item)
+	b.WriteString(" ")
 }
 
 // whenPart is a helper structure to describe SQLs "WHEN ... THEN ..." expression
@@ -61,13 +46,16 @@ type caseData struct {
 
 // ToSql implements Sqlizer
 func (d *caseData) ToSql() (sqlStr string, args []interface{}, err error) {
-	if len(d.WhenParts) == 0 {
-		err = errors.New("case expression must contain at lease one WHEN clause")
+	return renderToSql(d)
+}
 
-		return
+// RenderSql implements SqlRenderer
+func (d *caseData) RenderSql(w *SqlWriter) error {
+	if len(d.WhenParts) == 0 {
+		return errors.New("case expression must contain at lease one WHEN clause")
 	}
 
-	sql := sqlizerBuffer{}
+	sql := sqlizerBuffer{SqlWriter: w}
 
 	sql.WriteString("CASE ")
 	if d.What != nil {
@@ -88,7 +76,7 @@ func (d *caseData) ToSql() (sqlStr string, args []interface{}, err error) {
 
 	sql.WriteString("END")
 
-	return sql.ToSql()
+	return sql.err
 }
 
 // CaseBuilder builds SQL CASE construct which could be used as parts of queries.
@@ -100,6 +88,12 @@ func (b CaseBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+// RenderSql renders the query into w, see SqlRenderer.
+func (b CaseBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(caseData)
+	return data.RenderSql(w)
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b CaseBuilder) MustSql() (string, []interface{}) {
@@ -110,6 +104,11 @@ func (b CaseBuilder) MustSql() (string, []interface{}) {
 	return sql, args
 }
 
+// ToSqlFor builds the query into a SQL string and bound args in Dialect d.
+func (b CaseBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return statementToSql(b, d, d.PlaceholderFormat())
+}
+
 // what sets optional value for CASE construct "CASE [value] ..."
 func (b CaseBuilder) what(expr interface{}) CaseBuilder {
 	return builder.Set(b, "What", newPart(expr)).(CaseBuilder)
@@ -148,3 +148,24 @@ func TestCaseBuilderMustSql(t *testing.T) {
 	}()
 	Case("").MustSql()
 }
+
+func TestCaseBuilderToSqlFor(t *testing.T) {
+	b := Case().When(Eq{"n": 1}, "'one'").Else(Expr("?", 0))
+
+	sql, args, err := b.ToSqlFor(Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "CASE WHEN n = $1 THEN 'one' ELSE $2 END", sql)
+	assert.Equal(t, []interface{}{1, 0}, args)
+}
+
+func TestCaseBuilderToSqlForDialect(t *testing.T) {
+	b := Case().When(IsDistinctFrom{"a": 1}, "1").Else("0")
+
+	sql, args, err := b.ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "CASE WHEN NOT (a <=> ?) THEN 1 ELSE 0 END", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	_, _, err = b.ToSqlFor(Oracle)
+	assert.EqualError(t, err, "Oracle does not support IS DISTINCT FROM")
+}
@@ -0,0 +1,133 @@
+package squirrel
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// Comment adds comment tags for any child builders, see SelectBuilder.Comment.
+func (b StatementBuilderType) Comment(tags map[string]string) StatementBuilderType {
+	return builder.Append(b, "Comments", tags).(StatementBuilderType)
+}
+
+type commentKey struct{}
+
+// WithComment returns a copy of ctx with comment tags, which are added to the
+// comment of queries executed with ctx by ExecContextWith, QueryContextWith,
+// QueryRowContextWith and the Context methods of builders. Tags already in
+// ctx are kept unless tags replaces them.
+//
+// Ex:
+//     ctx = WithComment(ctx, map[string]string{"route": "/users/{id}"})
+func WithComment(ctx context.Context, tags map[string]string) context.Context {
+	return context.WithValue(ctx, commentKey{}, mergeComments(commentFromContext(ctx), tags))
+}
+
+func commentFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(commentKey{}).(map[string]string)
+	return tags
+}
+
+// commentedStatement is implemented by statement data, which render the
+// comment tags of a context with their own.
+type commentedStatement interface {
+	toSqlComment(tags map[string]string) (string, []interface{}, error)
+}
+
+// toSqlContext returns the SQL of s with the comment tags of ctx.
+//
+// Sqlizers other than the builders of this package get the tags of ctx as a
+// comment at the end of their SQL.
+func toSqlContext(ctx context.Context, s Sqlizer) (string, []interface{}, error) {
+	tags := commentFromContext(ctx)
+	if len(tags) == 0 {
+		return s.ToSql()
+	}
+	if st, ok := statementOf(s).(commentedStatement); ok {
+		return st.toSqlComment(tags)
+	}
+
+	query, args, err := s.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	return addComment(defaultDialect, query, tags), args, nil
+}
+
+// mergeComments returns the tags of all comments, with the tags of later
+// comments replacing those of earlier ones.
+func mergeComments(comments ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, tags := range comments {
+		for k, v := range tags {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// addComment adds a comment with tags to query where Dialect d keeps it: at
+// the start with FeatureLeadingComment, or else at the end, before a
+// terminating semicolon or on a new line after a trailing -- comment.
+func addComment(d Dialect, query string, tags map[string]string) string {
+	if len(tags) == 0 {
+		return query
+	}
+	comment := formatComment(tags)
+
+	if d.Supports(FeatureLeadingComment) {
+		return comment + " " + query
+	}
+	if endsInLineComment(query) {
+		return query + "\n" + comment
+	}
+
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return trimmed[:len(trimmed)-1] + " " + comment + ";"
+	}
+	return query + " " + comment
+}
+
+// endsInLineComment reports whether query ends inside a -- comment, which
+// would swallow anything appended to it.
+func endsInLineComment(query string) bool {
+	for i := 0; i < len(query); {
+		end := skippedEnd(query, i)
+		if end == i {
+			i++
+			continue
+		}
+		if end == len(query) && strings.HasPrefix(query[i:], "--") {
+			return strings.IndexByte(query[i:], '\n') == -1
+		}
+		i = end
+	}
+	return false
+}
+
+// formatComment formats tags as a sqlcommenter comment, e.g.
+// /*action='list',route='%2Fusers'*/, with keys in sorted order and keys and
+// values URL encoded, which leaves no quotes, placeholders or comment
+// terminators in the comment.
+func formatComment(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = commentEscape(k) + "='" + commentEscape(tags[k]) + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+func commentEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
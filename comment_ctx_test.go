@@ -0,0 +1,27 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithComment(t *testing.T) {
+	db := &DBStub{}
+	ctx := WithComment(ctx, map[string]string{"route": "/a", "action": "ctx"})
+	ctx = WithComment(ctx, map[string]string{"route": "/b"})
+
+	_, err := Delete("users").Where("id = ?", 1).
+		Comment(map[string]string{"action": "delete", "app": "api"}).
+		RunWith(db).ExecContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE id = ? /*action='ctx',app='api',route='%2Fb'*/", db.LastExecSql)
+
+	// builders passed directly
+	QueryContextWith(ctx, db, Select("*").From("users"))
+	assert.Equal(t, "SELECT * FROM users /*action='ctx',route='%2Fb'*/", db.LastQuerySql)
+
+	// other Sqlizers
+	QueryRowContextWith(ctx, db, Expr("SELECT 1"))
+	assert.Equal(t, "SELECT 1 /*action='ctx',route='%2Fb'*/", db.LastQueryRowSql)
+}
@@ -0,0 +1,76 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatComment(t *testing.T) {
+	c := formatComment(map[string]string{
+		"route":     "/users/{id}",
+		"action":    "it's */ done?",
+		"db driver": "pq",
+	})
+	assert.Equal(t, "/*action='it%27s%20%2A%2F%20done%3F',db%20driver='pq',route='%2Fusers%2F%7Bid%7D'*/", c)
+}
+
+func TestComment(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(Dollar).Comment(map[string]string{"app": "api", "action": "default"})
+
+	sql, args, err := sb.Select("*").From("users").
+		Where("id = ? AND note <> ':x'", 1).
+		Where(Expr("id IN (?)", Select("id").From("admins").Comment(map[string]string{"nested": "1"}))).
+		Comment(map[string]string{"action": "list"}).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = $1 AND note <> ':x' AND id IN (SELECT id FROM admins) /*action='list',app='api'*/", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _, err = Update("users").Set("a", 1).Comment(map[string]string{"x": "y"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET a = ? /*x='y'*/", sql)
+
+	sql, _, err = Select("a").From("t").Comment(nil).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t", sql)
+}
+
+func TestCommentDialects(t *testing.T) {
+	tags := map[string]string{"action": "sync"}
+
+	sql, _, err := Merge("stock").Using("deliveries d").On("id = d.id").
+		When(WhenMatched().Delete()).
+		Comment(tags).
+		ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO stock USING deliveries d ON (id = d.id) WHEN MATCHED THEN DELETE /*action='sync'*/;", sql)
+
+	sql, _, err = Select("1").Comment(tags).ToSqlFor(leadingCommentDialect{Postgres})
+	assert.NoError(t, err)
+	assert.Equal(t, "/*action='sync'*/ SELECT 1", sql)
+}
+
+type leadingCommentDialect struct {
+	Dialect
+}
+
+func (d leadingCommentDialect) Supports(feature Feature) bool {
+	return feature == FeatureLeadingComment || d.Dialect.Supports(feature)
+}
+
+func TestCommentAfterLineComment(t *testing.T) {
+	tags := map[string]string{"k": "v"}
+
+	sql, _, err := Select("*").From("t").Where("a = ?", 1).Suffix("-- trailing").Comment(tags).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = ? -- trailing\n/*k='v'*/", sql)
+
+	sql, _, err = Select("*").From("t").Where("a = '--'").Suffix("/* x */").Comment(tags).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = '--' /* x */ /*k='v'*/", sql)
+
+	sql, _, err = Select("*").From("t").Suffix("-- a\n;").Comment(tags).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t -- a\n /*k='v'*/;", sql)
+}
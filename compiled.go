@@ -0,0 +1,194 @@
+package squirrel
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Param is a placeholder arg of a CompiledQuery, whose value is bound by name
+// on each execution with CompiledQuery.Bind. It can be used wherever an arg
+// value can, e.g. in Where, Eq, Set or Values.
+//
+// Ex:
+//     q, err := Select("*").From("users").Where(Eq{"id": Param("id")}).RunWith(db).Compile()
+//     ...
+//     err = q.Bind(Named{"id": 1}).Scan(&user.ID, &user.Name)
+type Param string
+
+// CompiledQuery is a query built once into its SQL string, whose Param args
+// are bound on each execution. It is safe for concurrent use.
+//
+// The SQL of a CompiledQuery never changes, so with a StmtCache as the Runner
+// it is prepared only once.
+type CompiledQuery struct {
+	sql    string
+	args   []interface{}
+	params map[string]bool
+	runner BaseRunner
+	kind   string
+	hooks  []Hook
+}
+
+// compile builds s into a CompiledQuery run with runner.
+func compile(s Sqlizer, runner BaseRunner) (*CompiledQuery, error) {
+	sqlStr, args, err := s.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &CompiledQuery{sql: sqlStr, args: args, params: map[string]bool{}, runner: runner}
+	if st, ok := s.(hookedStatement); ok {
+		q.kind, q.hooks = st.statementKind(), st.statementHooks()
+	} else {
+		q.kind = statementKind(sqlStr)
+	}
+	for _, arg := range args {
+		if name, ok := paramName(arg); ok {
+			q.params[name] = true
+		}
+	}
+	return q, nil
+}
+
+// paramName returns the name of the Param of arg, which may be a
+// sql.NamedArg with a Param value.
+func paramName(arg interface{}) (string, bool) {
+	if named, ok := arg.(sql.NamedArg); ok {
+		arg = named.Value
+	}
+	p, ok := arg.(Param)
+	return string(p), ok
+}
+
+// SQL returns the SQL string of the query.
+func (q *CompiledQuery) SQL() string {
+	return q.sql
+}
+
+// Params returns the sorted names of the Params of the query.
+func (q *CompiledQuery) Params() []string {
+	names := make([]string, 0, len(q.params))
+	for name := range q.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunWith returns a copy of the query run with runner.
+func (q *CompiledQuery) RunWith(runner BaseRunner) *CompiledQuery {
+	c := *q
+	c.runner = wrapRunner(runner)
+	return &c
+}
+
+// Prepare prepares the query with the Runner set by RunWith, which must be a
+// Preparer like StmtCache.
+func (q *CompiledQuery) Prepare() (*sql.Stmt, error) {
+	if q.runner == nil {
+		return nil, RunnerNotSet
+	}
+	preparer, ok := q.runner.(Preparer)
+	if !ok {
+		return nil, fmt.Errorf("cannot Prepare; Runner is not a Preparer")
+	}
+	return preparer.Prepare(q.sql)
+}
+
+// Bind binds values to the Params of the query. Params without a value and
+// values without a Param are errors, returned when the BoundQuery is used.
+func (q *CompiledQuery) Bind(values Named) BoundQuery {
+	b := BoundQuery{query: q}
+
+	var unknown []string
+	for name := range values {
+		if !q.params[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		b.err = fmt.Errorf("no params named %s", strings.Join(unknown, ", "))
+		return b
+	}
+
+	b.args = make([]interface{}, len(q.args))
+	for i, arg := range q.args {
+		name, ok := paramName(arg)
+		if !ok {
+			b.args[i] = arg
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok {
+			b.err = fmt.Errorf("missing value for param %q", name)
+			return b
+		}
+		if named, ok := arg.(sql.NamedArg); ok {
+			b.args[i] = sql.Named(named.Name, value)
+		} else {
+			b.args[i] = value
+		}
+	}
+	return b
+}
+
+// BoundQuery is a CompiledQuery with values bound to its Params.
+type BoundQuery struct {
+	query *CompiledQuery
+	args  []interface{}
+	err   error
+}
+
+// ToSql returns the SQL string of the query and the bound args.
+func (b BoundQuery) ToSql() (string, []interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.query.sql, b.args, nil
+}
+
+func (b BoundQuery) statementKind() string {
+	return b.query.kind
+}
+
+func (b BoundQuery) statementHooks() []Hook {
+	return b.query.hooks
+}
+
+// Exec executes the query with the Runner of the CompiledQuery.
+func (b BoundQuery) Exec() (sql.Result, error) {
+	if b.query.runner == nil {
+		return nil, RunnerNotSet
+	}
+	return ExecWith(b.query.runner, b)
+}
+
+// Query executes the query with the Runner of the CompiledQuery.
+func (b BoundQuery) Query() (*sql.Rows, error) {
+	if b.query.runner == nil {
+		return nil, RunnerNotSet
+	}
+	return QueryWith(b.query.runner, b)
+}
+
+// QueryRow executes the query with the Runner of the CompiledQuery, which must
+// be a QueryRower.
+func (b BoundQuery) QueryRow() RowScanner {
+	if b.query.runner == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := b.query.runner.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return QueryRowWith(queryRower, b)
+}
+
+// Scan is a shortcut for QueryRow().Scan.
+func (b BoundQuery) Scan(dest ...interface{}) error {
+	return b.QueryRow().Scan(dest...)
+}
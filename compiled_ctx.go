@@ -0,0 +1,63 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ExecContext executes the query with the Runner of the CompiledQuery.
+func (b BoundQuery) ExecContext(ctx context.Context) (sql.Result, error) {
+	if b.query.runner == nil {
+		return nil, RunnerNotSet
+	}
+	ctxRunner, ok := b.query.runner.(ExecerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return ExecContextWith(ctx, ctxRunner, b)
+}
+
+// QueryContext executes the query with the Runner of the CompiledQuery.
+func (b BoundQuery) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if b.query.runner == nil {
+		return nil, RunnerNotSet
+	}
+	ctxRunner, ok := b.query.runner.(QueryerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return QueryContextWith(ctx, ctxRunner, b)
+}
+
+// QueryRowContext executes the query with the Runner of the CompiledQuery.
+func (b BoundQuery) QueryRowContext(ctx context.Context) RowScanner {
+	if b.query.runner == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := b.query.runner.(QueryRowerContext)
+	if !ok {
+		if _, ok := b.query.runner.(QueryerContext); !ok {
+			return &Row{err: RunnerNotQueryRunner}
+		}
+		return &Row{err: NoContextSupport}
+	}
+	return QueryRowContextWith(ctx, queryRower, b)
+}
+
+// ScanContext is a shortcut for QueryRowContext().Scan.
+func (b BoundQuery) ScanContext(ctx context.Context, dest ...interface{}) error {
+	return b.QueryRowContext(ctx).Scan(dest...)
+}
+
+// PrepareContext prepares the query with the Runner set by RunWith, which
+// must be a PreparerContext like StmtCache.
+func (q *CompiledQuery) PrepareContext(ctx context.Context) (*sql.Stmt, error) {
+	if q.runner == nil {
+		return nil, RunnerNotSet
+	}
+	preparer, ok := q.runner.(PreparerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return preparer.PrepareContext(ctx, q.sql)
+}
@@ -0,0 +1,33 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundQueryContextRunners(t *testing.T) {
+	db := &DBStub{}
+	q, err := Select("test").Where("id = ?", Param("id")).RunWith(db).Compile()
+	assert.NoError(t, err)
+	b := q.Bind(Named{"id": 1})
+
+	expectedSql := "SELECT test WHERE id = ?"
+
+	b.ExecContext(ctx)
+	assert.Equal(t, expectedSql, db.LastExecSql)
+	assert.Equal(t, []interface{}{1}, db.LastExecArgs)
+
+	b.QueryContext(ctx)
+	assert.Equal(t, expectedSql, db.LastQuerySql)
+
+	b.QueryRowContext(ctx)
+	assert.Equal(t, expectedSql, db.LastQueryRowSql)
+
+	err = b.ScanContext(ctx)
+	assert.NoError(t, err)
+
+	_, err = q.RunWith(NewStmtCache(db)).PrepareContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSql, db.LastPrepareSql)
+}
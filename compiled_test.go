@@ -0,0 +1,104 @@
+package squirrel
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledQuery(t *testing.T) {
+	db := &DBStub{}
+	q, err := Select("*").
+		From("items").
+		Where(Eq{"owner": Param("owner")}).
+		Where("kind = ? AND (size > ? OR size < -?)", "a", Param("size"), Param("size")).
+		PlaceholderFormat(Dollar).
+		RunWith(db).
+		Compile()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM items WHERE owner = $1 AND kind = $2 AND (size > $3 OR size < -$4)"
+	assert.Equal(t, expectedSql, q.SQL())
+	assert.Equal(t, []string{"owner", "size"}, q.Params())
+
+	_, err = q.Bind(Named{"owner": 1, "size": 10}).Query()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSql, db.LastQuerySql)
+	assert.Equal(t, []interface{}{1, "a", 10, 10}, db.LastQueryArgs)
+
+	err = q.Bind(Named{"owner": 2, "size": 20}).Scan()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSql, db.LastQueryRowSql)
+	assert.Equal(t, []interface{}{2, "a", 20, 20}, db.LastQueryRowArgs)
+}
+
+func TestCompiledQueryBuilders(t *testing.T) {
+	db := &DBStub{}
+
+	q, err := Insert("t").Columns("a", "b").Values(Param("a"), 2).RunWith(db).Compile()
+	assert.NoError(t, err)
+	_, err = q.Bind(Named{"a": 1}).Exec()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (a,b) VALUES (?,?)", db.LastExecSql)
+	assert.Equal(t, []interface{}{1, 2}, db.LastExecArgs)
+
+	q, err = Update("t").Set("a", Param("a")).Where("id = ?", Param("id")).Compile()
+	assert.NoError(t, err)
+	sql, args, err := q.Bind(Named{"a": 1, "id": 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ? WHERE id = ?", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	q, err = Delete("t").Where(Eq{"id": Param("id")}).Compile()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id"}, q.Params())
+}
+
+func TestCompiledQueryNamedArgs(t *testing.T) {
+	q, err := Select("*").
+		From("t").
+		Where("a = :a OR b = :a", Named{"a": Param("x")}).
+		Dialect(SQLServer).
+		Compile()
+	assert.NoError(t, err)
+
+	sqlStr, args, err := q.Bind(Named{"x": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = @a OR b = @a", sqlStr)
+	assert.Equal(t, []interface{}{sql.Named("a", 1)}, args)
+}
+
+func TestCompiledQueryBindErrors(t *testing.T) {
+	q, err := Select("*").From("t").Where("a = ? AND b = ?", Param("a"), Param("b")).Compile()
+	assert.NoError(t, err)
+
+	_, _, err = q.Bind(Named{"a": 1}).ToSql()
+	assert.EqualError(t, err, `missing value for param "b"`)
+
+	_, _, err = q.Bind(Named{"a": 1, "b": 2, "d": 4, "c": 3}).ToSql()
+	assert.EqualError(t, err, "no params named c, d")
+
+	_, err = q.Bind(Named{"a": 1, "b": 2}).Exec()
+	assert.Equal(t, RunnerNotSet, err)
+
+	_, err = Select().Compile()
+	assert.Error(t, err)
+}
+
+func TestCompiledQueryStmtCache(t *testing.T) {
+	db := &DBStub{}
+	q, err := Select("*").From("t").Where(Eq{"id": Param("id")}).Compile()
+	assert.NoError(t, err)
+
+	_, err = q.Prepare()
+	assert.Equal(t, RunnerNotSet, err)
+
+	q = q.RunWith(NewStmtCache(db))
+	_, err = q.Prepare()
+	assert.NoError(t, err)
+	_, err = q.Prepare()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE id = ?", db.LastPrepareSql)
+	assert.Equal(t, 1, db.PrepareCount)
+}
@@ -0,0 +1,309 @@
+package squirrel
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lann/builder"
+)
+
+type compoundSelectData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           BaseRunner
+	Hooks             []Hook
+	Comments          []map[string]string
+	Parts             []compoundPart
+	OrderByParts      []Sqlizer
+	Limit             string
+	Offset            string
+	Suffixes          []Sqlizer
+}
+
+// compoundPart is a single arm of a compound select along with the set
+// operator joining it to the previous arm.
+type compoundPart struct {
+	operator string
+	query    SelectBuilder
+}
+
+func newCompoundParts(operator string, selects []SelectBuilder) []compoundPart {
+	parts := make([]compoundPart, len(selects))
+	for i, sb := range selects {
+		parts[i] = compoundPart{operator: operator, query: sb}
+	}
+	return parts
+}
+
+// needsParens reports whether the arm has clauses that would otherwise be
+// parsed as applying to the whole compound select.
+func (p compoundPart) needsParens() bool {
+	data := builder.GetStruct(p.query).(selectData)
+	return len(data.OrderByParts) > 0 || len(data.Limit) > 0 || len(data.Offset) > 0
+}
+
+func (d *compoundSelectData) Exec(b Sqlizer) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return ExecWith(d.RunWith, b)
+}
+
+func (d *compoundSelectData) Query(b Sqlizer) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return QueryWith(d.RunWith, b)
+}
+
+func (d *compoundSelectData) QueryRow(b Sqlizer) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := d.RunWith.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return QueryRowWith(queryRower, b)
+}
+
+func (d *compoundSelectData) statementKind() string {
+	return "SELECT"
+}
+
+func (d *compoundSelectData) statementHooks() []Hook {
+	return d.Hooks
+}
+
+func (d *compoundSelectData) ToSql() (sqlStr string, args []interface{}, err error) {
+	return d.toSqlComment(nil)
+}
+
+func (d *compoundSelectData) toSqlComment(tags map[string]string) (string, []interface{}, error) {
+	return statementToSql(d, d.Dialect, d.PlaceholderFormat, append(d.Comments, tags)...)
+}
+
+func (d *compoundSelectData) RenderSql(w *SqlWriter) error {
+	if len(d.Parts) == 0 {
+		return errors.New("compound select statements must have at least one select")
+	}
+
+	dialect := w.statementDialect(d.Dialect)
+	_, limit, err := limitToSql(dialect, d.Limit, d.Offset, len(d.OrderByParts) > 0, false)
+	if err != nil {
+		return err
+	}
+
+	for i, part := range d.Parts {
+		if i > 0 {
+			w.WriteString(" ")
+			w.WriteString(part.operator)
+			w.WriteString(" ")
+		}
+
+		if part.needsParens() {
+			if !dialect.Supports(FeatureParenthesizedSelects) {
+				w.WriteString("SELECT * FROM ")
+			}
+			err = w.writeParenthesized(part.query)
+		} else {
+			err = w.WriteSqlizer(part.query)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(d.OrderByParts) > 0 {
+		w.WriteString(" ORDER BY ")
+		if err := w.writeSqlizers(d.OrderByParts, ", "); err != nil {
+			return err
+		}
+	}
+
+	w.WriteString(limit)
+
+	if len(d.Suffixes) > 0 {
+		w.WriteString(" ")
+
+		if err := w.writeSqlizers(d.Suffixes, " "); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Builder
+
+// CompoundSelectBuilder builds SQL statements combining SELECT statements
+// with the UNION, UNION ALL, INTERSECT and EXCEPT set operators.
+//
+// Arms are rendered in the order they were added; note that standard SQL
+// gives INTERSECT a higher precedence than UNION and EXCEPT.
+type CompoundSelectBuilder builder.Builder
+
+func init() {
+	builder.Register(CompoundSelectBuilder{}, compoundSelectData{})
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b CompoundSelectBuilder) PlaceholderFormat(f PlaceholderFormat) CompoundSelectBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(CompoundSelectBuilder)
+}
+
+// Dialect sets the SQL Dialect (e.g. Postgres or SQLServer) of the query,
+// along with its PlaceholderFormat.
+func (b CompoundSelectBuilder) Dialect(d Dialect) CompoundSelectBuilder {
+	return setDialect(b, d).(CompoundSelectBuilder)
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g. Query.
+func (b CompoundSelectBuilder) RunWith(runner BaseRunner) CompoundSelectBuilder {
+	return setRunWith(b, runner).(CompoundSelectBuilder)
+}
+
+// Exec builds and Execs the query with the Runner set by RunWith.
+func (b CompoundSelectBuilder) Exec() (sql.Result, error) {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.Exec(b)
+}
+
+// Query builds and Querys the query with the Runner set by RunWith.
+func (b CompoundSelectBuilder) Query() (*sql.Rows, error) {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.Query(b)
+}
+
+// QueryRow builds and QueryRows the query with the Runner set by RunWith.
+func (b CompoundSelectBuilder) QueryRow() RowScanner {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.QueryRow(b)
+}
+
+// Scan is a shortcut for QueryRow().Scan.
+func (b CompoundSelectBuilder) Scan(dest ...interface{}) error {
+	return b.QueryRow().Scan(dest...)
+}
+
+// SQL methods
+
+// ToSql builds the query into a SQL string and bound args.
+func (b CompoundSelectBuilder) ToSql() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.ToSql()
+}
+
+// RenderSql renders the query into w, see SqlRenderer.
+func (b CompoundSelectBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.RenderSql(w)
+}
+
+// MustSql builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (b CompoundSelectBuilder) MustSql() (string, []interface{}) {
+	sql, args, err := b.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// ToSqlFor builds the query into a SQL string and bound args for Dialect d,
+// regardless of the Dialect and PlaceholderFormat set on the builder. The
+// builder itself is not modified.
+func (b CompoundSelectBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return b.Dialect(d).ToSql()
+}
+
+// Compile builds the query once into a CompiledQuery, which binds the values
+// of its Param args on each execution with the Runner set by RunWith.
+func (b CompoundSelectBuilder) Compile() (*CompiledQuery, error) {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return compile(&data, data.RunWith)
+}
+
+func (b CompoundSelectBuilder) statement() Sqlizer {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return &data
+}
+
+// Union adds selects to the query, combined with UNION.
+func (b CompoundSelectBuilder) Union(selects ...SelectBuilder) CompoundSelectBuilder {
+	return b.combine("UNION", selects)
+}
+
+// UnionAll adds selects to the query, combined with UNION ALL.
+func (b CompoundSelectBuilder) UnionAll(selects ...SelectBuilder) CompoundSelectBuilder {
+	return b.combine("UNION ALL", selects)
+}
+
+// Intersect adds selects to the query, combined with INTERSECT.
+func (b CompoundSelectBuilder) Intersect(selects ...SelectBuilder) CompoundSelectBuilder {
+	return b.combine("INTERSECT", selects)
+}
+
+// Except adds selects to the query, combined with EXCEPT.
+func (b CompoundSelectBuilder) Except(selects ...SelectBuilder) CompoundSelectBuilder {
+	return b.combine("EXCEPT", selects)
+}
+
+func (b CompoundSelectBuilder) combine(operator string, selects []SelectBuilder) CompoundSelectBuilder {
+	return builder.Extend(b, "Parts", newCompoundParts(operator, selects)).(CompoundSelectBuilder)
+}
+
+// OrderByClause adds ORDER BY clause to the whole compound query.
+func (b CompoundSelectBuilder) OrderByClause(pred interface{}, args ...interface{}) CompoundSelectBuilder {
+	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(CompoundSelectBuilder)
+}
+
+// OrderBy adds ORDER BY expressions to the whole compound query.
+func (b CompoundSelectBuilder) OrderBy(orderBys ...string) CompoundSelectBuilder {
+	for _, orderBy := range orderBys {
+		b = b.OrderByClause(orderBy)
+	}
+
+	return b
+}
+
+// Limit sets a LIMIT clause on the whole compound query.
+func (b CompoundSelectBuilder) Limit(limit uint64) CompoundSelectBuilder {
+	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(CompoundSelectBuilder)
+}
+
+// RemoveLimit removes LIMIT clause.
+func (b CompoundSelectBuilder) RemoveLimit() CompoundSelectBuilder {
+	return builder.Delete(b, "Limit").(CompoundSelectBuilder)
+}
+
+// Offset sets a OFFSET clause on the whole compound query.
+func (b CompoundSelectBuilder) Offset(offset uint64) CompoundSelectBuilder {
+	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(CompoundSelectBuilder)
+}
+
+// RemoveOffset removes OFFSET clause.
+func (b CompoundSelectBuilder) RemoveOffset() CompoundSelectBuilder {
+	return builder.Delete(b, "Offset").(CompoundSelectBuilder)
+}
+
+// Suffix adds an expression to the end of the query
+func (b CompoundSelectBuilder) Suffix(sql string, args ...interface{}) CompoundSelectBuilder {
+	return b.SuffixExpr(Expr(sql, args...))
+}
+
+// SuffixExpr adds an expression to the end of the query
+func (b CompoundSelectBuilder) SuffixExpr(expr Sqlizer) CompoundSelectBuilder {
+	return builder.Append(b, "Suffixes", expr).(CompoundSelectBuilder)
+}
+
+// Comment adds a comment with tags to the query, see SelectBuilder.Comment.
+func (b CompoundSelectBuilder) Comment(tags map[string]string) CompoundSelectBuilder {
+	return builder.Append(b, "Comments", tags).(CompoundSelectBuilder)
+}
@@ -0,0 +1,67 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lann/builder"
+)
+
+func (d *compoundSelectData) ExecContext(ctx context.Context, b Sqlizer) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	ctxRunner, ok := d.RunWith.(ExecerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return ExecContextWith(ctx, ctxRunner, b)
+}
+
+func (d *compoundSelectData) QueryContext(ctx context.Context, b Sqlizer) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	ctxRunner, ok := d.RunWith.(QueryerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return QueryContextWith(ctx, ctxRunner, b)
+}
+
+func (d *compoundSelectData) QueryRowContext(ctx context.Context, b Sqlizer) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := d.RunWith.(QueryRowerContext)
+	if !ok {
+		if _, ok := d.RunWith.(QueryerContext); !ok {
+			return &Row{err: RunnerNotQueryRunner}
+		}
+		return &Row{err: NoContextSupport}
+	}
+	return QueryRowContextWith(ctx, queryRower, b)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b CompoundSelectBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.ExecContext(ctx, b)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by RunWith.
+func (b CompoundSelectBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.QueryContext(ctx, b)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by RunWith.
+func (b CompoundSelectBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(compoundSelectData)
+	return data.QueryRowContext(ctx, b)
+}
+
+// ScanContext is a shortcut for QueryRowContext().Scan.
+func (b CompoundSelectBuilder) ScanContext(ctx context.Context, dest ...interface{}) error {
+	return b.QueryRowContext(ctx).Scan(dest...)
+}
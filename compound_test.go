@@ -0,0 +1,167 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompoundSelectBuilderToSql(t *testing.T) {
+	b := Union(
+		Select("id", "name").From("users").Where("active = ?", true),
+		Select("id", "name").From("admins").Where("level > ?", 1),
+	).
+		UnionAll(Select("id", "name").From("guests")).
+		OrderByClause("name = ? DESC", "root").
+		OrderBy("id").
+		Limit(10).
+		Offset(20).
+		Suffix("-- ?", 2)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql :=
+		"SELECT id, name FROM users WHERE active = ? " +
+			"UNION SELECT id, name FROM admins WHERE level > ? " +
+			"UNION ALL SELECT id, name FROM guests " +
+			"ORDER BY name = ? DESC, id LIMIT 10 OFFSET 20 -- ?"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{true, 1, "root", 2}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestCompoundSelectBuilderOperators(t *testing.T) {
+	a := Select("x").From("a")
+	b := Select("x").From("b")
+	c := Select("x").From("c")
+
+	sql, _, err := Intersect(a, b).Except(c).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT x FROM a INTERSECT SELECT x FROM b EXCEPT SELECT x FROM c", sql)
+
+	sql, _, err = UnionAll(a, b, c).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT x FROM a UNION ALL SELECT x FROM b UNION ALL SELECT x FROM c", sql)
+}
+
+func TestCompoundSelectBuilderParenthesizedArms(t *testing.T) {
+	b := Union(
+		Select("x").From("a").OrderBy("x").Limit(1),
+		Select("x").From("b").Offset(2),
+		Select("x").From("c"),
+	)
+
+	sql, _, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "(SELECT x FROM a ORDER BY x LIMIT 1) " +
+		"UNION (SELECT x FROM b OFFSET 2) " +
+		"UNION SELECT x FROM c"
+	assert.Equal(t, expectedSql, sql)
+
+	// SQLite does not allow parenthesized selects
+	sql, _, err = UnionAll(
+		Select("x").From("a").OrderBy("x").Limit(1),
+		Select("x").From("c"),
+	).ToSqlFor(SQLite)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT x FROM a ORDER BY x LIMIT 1) UNION ALL SELECT x FROM c", sql)
+}
+
+func TestCompoundSelectBuilderPlaceholders(t *testing.T) {
+	b := Union(
+		Select("x").From("a").Where("y = ?", 1).PlaceholderFormat(Dollar),
+		Select("x").From("b").Where("y = ?", 2).PlaceholderFormat(Dollar),
+	).
+		Suffix("FETCH FIRST ? ROWS ONLY", 3).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT x FROM a WHERE y = $1 UNION SELECT x FROM b WHERE y = $2 FETCH FIRST $3 ROWS ONLY"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2, 3}, args)
+}
+
+func TestCompoundSelectBuilderStatementBuilder(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(Dollar)
+	b := sb.Union(sb.Select("x").From("a").Where("y = ?", 1), sb.Select("x").From("b").Where("y = ?", 2))
+
+	sql, _, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT x FROM a WHERE y = $1 UNION SELECT x FROM b WHERE y = $2", sql)
+}
+
+func TestCompoundSelectBuilderFromSelect(t *testing.T) {
+	u := Union(Select("x").From("a").Where("y = ?", 1), Select("x").From("b")).
+		PlaceholderFormat(Dollar)
+	b := Select("count(*)").FromSelect(u, "u").Where("x > ?", 2).PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT count(*) FROM (SELECT x FROM a WHERE y = $1 UNION SELECT x FROM b) AS u WHERE x > $2"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
+
+func TestCompoundSelectBuilderInsertSelect(t *testing.T) {
+	u := Union(Select("x").From("a").Where("y = ?", 1), Select("x").From("b").Where("y = ?", 2))
+	b := Insert("c").Columns("x").Select(u)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO c (x) SELECT x FROM a WHERE y = ? UNION SELECT x FROM b WHERE y = ?"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
+
+func TestCompoundSelectBuilderToSqlErr(t *testing.T) {
+	_, _, err := Union().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Union(Select("x"), Select()).ToSql()
+	assert.Error(t, err)
+}
+
+func TestCompoundSelectBuilderMustSql(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("TestCompoundSelectBuilderMustSql should have panicked!")
+		}
+	}()
+	Union().MustSql()
+}
+
+func TestCompoundSelectBuilderRunners(t *testing.T) {
+	db := &DBStub{}
+	b := Union(Select("a"), Select("b")).RunWith(db)
+
+	expectedSql := "SELECT a UNION SELECT b"
+
+	b.Exec()
+	assert.Equal(t, expectedSql, db.LastExecSql)
+
+	b.Query()
+	assert.Equal(t, expectedSql, db.LastQuerySql)
+
+	b.QueryRow()
+	assert.Equal(t, expectedSql, db.LastQueryRowSql)
+
+	err := b.Scan()
+	assert.NoError(t, err)
+}
+
+func TestCompoundSelectBuilderNoRunner(t *testing.T) {
+	b := Union(Select("a"), Select("b"))
+
+	_, err := b.Query()
+	assert.Equal(t, RunnerNotSet, err)
+
+	err = b.Scan()
+	assert.Equal(t, RunnerNotSet, err)
+}
@@ -0,0 +1,86 @@
+package squirrel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// cte describes a single common table expression of a WITH clause.
+type cte struct {
+	name      string
+	columns   []string
+	recursive bool
+	// materialized is the optional MATERIALIZED or NOT MATERIALIZED hint.
+	materialized string
+	expr         Sqlizer
+}
+
+func newCTE(name string, as Sqlizer) cte {
+	return cte{name: name, expr: as}
+}
+
+func (c cte) ToSql() (sqlStr string, args []interface{}, err error) {
+	return renderToSql(c)
+}
+
+func (c cte) RenderSql(w *SqlWriter) error {
+	if len(c.name) == 0 {
+		return errors.New("common table expressions must have a name")
+	}
+	if c.expr == nil {
+		return fmt.Errorf("common table expression %s has no query", c.name)
+	}
+
+	w.WriteString(c.name)
+	if len(c.columns) > 0 {
+		w.WriteString("(")
+		w.WriteString(strings.Join(c.columns, ", "))
+		w.WriteString(")")
+	}
+	w.WriteString(" AS ")
+	if len(c.materialized) > 0 {
+		w.WriteString(c.materialized)
+		w.WriteString(" ")
+	}
+	return w.writeParenthesized(c.expr)
+}
+
+// writeWithClause writes the WITH clause for ctes, followed by a space, to w.
+// A single recursive expression makes the whole clause WITH RECURSIVE, unless
+// dialect d has no RECURSIVE keyword.
+func writeWithClause(w *SqlWriter, d Dialect, ctes []cte) error {
+	if len(ctes) == 0 {
+		return nil
+	}
+
+	if err := requireFeature(d, FeatureCTE); err != nil {
+		return err
+	}
+
+	recursive := false
+	for _, c := range ctes {
+		recursive = recursive || c.recursive
+		if len(c.materialized) > 0 {
+			if err := requireFeature(d, FeatureMaterializedCTE); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.WriteString("WITH ")
+	if recursive && d.Supports(FeatureRecursiveKeyword) {
+		w.WriteString("RECURSIVE ")
+	}
+
+	parts := make([]Sqlizer, len(ctes))
+	for i, c := range ctes {
+		parts[i] = c
+	}
+	if err := w.writeSqlizers(parts, ", "); err != nil {
+		return err
+	}
+
+	w.WriteString(" ")
+	return nil
+}
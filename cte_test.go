@@ -0,0 +1,127 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderWith(t *testing.T) {
+	recent := Select("id").From("orders").Where("created_at > ?", 1).PlaceholderFormat(Dollar)
+	b := Select("*").
+		With("recent", recent).
+		From("recent").
+		Where("id > ?", 2).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH recent AS (SELECT id FROM orders WHERE created_at > $1) " +
+		"SELECT * FROM recent WHERE id > $2"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
+
+func TestSelectBuilderWithPrefix(t *testing.T) {
+	b := Select("*").
+		Prefix("/* ? */", 0).
+		With("a", Select("x").From("t").Where("y = ?", 1)).
+		With("b", Expr("SELECT ?", 2)).
+		From("a, b")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "/* ? */ WITH a AS (SELECT x FROM t WHERE y = ?), b AS (SELECT ?) " +
+		"SELECT * FROM a, b"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{0, 1, 2}, args)
+}
+
+func TestSelectBuilderWithRecursive(t *testing.T) {
+	b := Select("n").
+		With("base", Select("1")).
+		WithRecursive("t", []string{"n"}, Expr("SELECT 1 UNION ALL SELECT n+1 FROM t WHERE n < ?", 100)).
+		From("t")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH RECURSIVE base AS (SELECT 1), " +
+		"t(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM t WHERE n < ?) " +
+		"SELECT n FROM t"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{100}, args)
+}
+
+func TestSelectBuilderWithMaterialized(t *testing.T) {
+	b := Select("*").
+		WithMaterialized("m", Select("a").From("x")).
+		WithNotMaterialized("n", Select("b").From("y")).
+		From("m, n")
+
+	sql, _, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH m AS MATERIALIZED (SELECT a FROM x), n AS NOT MATERIALIZED (SELECT b FROM y) " +
+		"SELECT * FROM m, n"
+	assert.Equal(t, expectedSql, sql)
+}
+
+func TestSelectBuilderWithErr(t *testing.T) {
+	_, _, err := Select("*").With("", Select("a")).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").With("a", nil).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").With("a", Select()).ToSql()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderWith(t *testing.T) {
+	b := Insert("archive").
+		With("old", Delete("orders").Where("created_at < ?", 1).Suffix("RETURNING *")).
+		Select(Select("*").From("old").Where("kept = ?", false)).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH old AS (DELETE FROM orders WHERE created_at < $1 RETURNING *) " +
+		"INSERT INTO archive SELECT * FROM old WHERE kept = $2"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, false}, args)
+}
+
+func TestUpdateBuilderWith(t *testing.T) {
+	b := Update("users").
+		With("banned", Select("user_id").From("bans").Where("until > ?", 1)).
+		Set("active", false).
+		Where("id IN (SELECT user_id FROM banned)").
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH banned AS (SELECT user_id FROM bans WHERE until > $1) " +
+		"UPDATE users SET active = $2 WHERE id IN (SELECT user_id FROM banned)"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, false}, args)
+}
+
+func TestDeleteBuilderWith(t *testing.T) {
+	b := Delete("sessions").
+		With("expired", Select("id").From("sessions").Where("expires_at < ?", 1)).
+		Where("id IN (SELECT id FROM expired) AND user_id = ?", 2).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH expired AS (SELECT id FROM sessions WHERE expires_at < $1) " +
+		"DELETE FROM sessions WHERE id IN (SELECT id FROM expired) AND user_id = $2"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
@@ -1,86 +1,133 @@
 package squirrel
 
 import (
-	"bytes"
 	"database/sql"
 	"fmt"
-	"strings"
 
 	"github.com/lann/builder"
 )
 
 type deleteData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
+	Hooks             []Hook
+	Comments          []map[string]string
 	Prefixes          []Sqlizer
+	CTEs              []cte
 	From              string
 	WhereParts        []Sqlizer
 	OrderBys          []string
 	Limit             string
 	Offset            string
+	Returning         []string
 	Suffixes          []Sqlizer
 }
 
-func (d *deleteData) Exec() (sql.Result, error) {
+func (d *deleteData) Exec(b Sqlizer) (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return ExecWith(d.RunWith, d)
+	return ExecWith(d.RunWith, b)
+}
+
+func (d *deleteData) Query(b Sqlizer) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return QueryWith(d.RunWith, b)
+}
+
+func (d *deleteData) QueryRow(b Sqlizer) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := d.RunWith.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return QueryRowWith(queryRower, b)
+}
+
+func (d *deleteData) statementKind() string {
+	return "DELETE"
+}
+
+func (d *deleteData) statementHooks() []Hook {
+	return d.Hooks
 }
 
 func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
+	return d.toSqlComment(nil)
+}
+
+func (d *deleteData) toSqlComment(tags map[string]string) (string, []interface{}, error) {
+	return statementToSql(d, d.Dialect, d.PlaceholderFormat, append(d.Comments, tags)...)
+}
+
+func (d *deleteData) RenderSql(w *SqlWriter) error {
 	if len(d.From) == 0 {
-		err = fmt.Errorf("delete statements must specify a From table")
-		return
+		return fmt.Errorf("delete statements must specify a From table")
 	}
 
-	sql := &bytes.Buffer{}
+	dialect := w.statementDialect(d.Dialect)
+	top, limit, err := dmlLimitToSql(dialect, d.OrderBys, d.Limit, d.Offset)
+	if err != nil {
+		return err
+	}
 
-	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+	output := false
+	if len(d.Returning) > 0 {
+		output, err = useOutput(dialect)
 		if err != nil {
-			return
+			return err
 		}
-
-		sql.WriteString(" ")
 	}
 
-	sql.WriteString("DELETE FROM ")
-	sql.WriteString(d.From)
-
-	if len(d.WhereParts) > 0 {
-		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
-		if err != nil {
-			return
+	if len(d.Prefixes) > 0 {
+		if err := w.writeSqlizers(d.Prefixes, " "); err != nil {
+			return err
 		}
+
+		w.WriteString(" ")
+	}
+
+	if err := writeWithClause(w, dialect, d.CTEs); err != nil {
+		return err
 	}
 
-	if len(d.OrderBys) > 0 {
-		sql.WriteString(" ORDER BY ")
-		sql.WriteString(strings.Join(d.OrderBys, ", "))
+	w.WriteString("DELETE ")
+	w.WriteString(top)
+	w.WriteString("FROM ")
+	w.WriteString(d.From)
+
+	if len(d.Returning) > 0 && output {
+		w.WriteString(" ")
+		w.WriteString(returningClause(d.Returning, true, "DELETED"))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(d.WhereParts) > 0 {
+		w.WriteString(" WHERE ")
+		if err := w.writeSqlizers(d.WhereParts, " AND "); err != nil {
+			return err
+		}
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	w.WriteString(limit)
+
+	if len(d.Returning) > 0 && !output {
+		w.WriteString(" ")
+		w.WriteString(returningClause(d.Returning, false, ""))
 	}
 
 	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+		w.WriteString(" ")
+		if err := w.writeSqlizers(d.Suffixes, " "); err != nil {
+			return err
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
-	return
+	return nil
 }
 
 // Builder
@@ -100,6 +147,12 @@ func (b DeleteBuilder) PlaceholderFormat(f PlaceholderFormat) DeleteBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(DeleteBuilder)
 }
 
+// Dialect sets the SQL Dialect (e.g. Postgres or SQLServer) of the query,
+// along with its PlaceholderFormat.
+func (b DeleteBuilder) Dialect(d Dialect) DeleteBuilder {
+	return setDialect(b, d).(DeleteBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -110,7 +163,24 @@ func (b DeleteBuilder) RunWith(runner BaseRunner) DeleteBuilder {
 // Exec builds and Execs the query with the Runner set by RunWith.
 func (b DeleteBuilder) Exec() (sql.Result, error) {
 	data := builder.GetStruct(b).(deleteData)
-	return data.Exec()
+	return data.Exec(b)
+}
+
+// Query builds and Querys the query with the Runner set by RunWith.
+func (b DeleteBuilder) Query() (*sql.Rows, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return data.Query(b)
+}
+
+// QueryRow builds and QueryRows the query with the Runner set by RunWith.
+func (b DeleteBuilder) QueryRow() RowScanner {
+	data := builder.GetStruct(b).(deleteData)
+	return data.QueryRow(b)
+}
+
+// Scan is a shortcut for QueryRow().Scan.
+func (b DeleteBuilder) Scan(dest ...interface{}) error {
+	return b.QueryRow().Scan(dest...)
 }
 
 // SQL methods
@@ -121,6 +191,12 @@ func (b DeleteBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+// RenderSql renders the query into w, see SqlRenderer.
+func (b DeleteBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(deleteData)
+	return data.RenderSql(w)
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b DeleteBuilder) MustSql() (string, []interface{}) {
@@ -131,6 +207,25 @@ func (b DeleteBuilder) MustSql() (string, []interface{}) {
 	return sql, args
 }
 
+// ToSqlFor builds the query into a SQL string and bound args for Dialect d,
+// regardless of the Dialect and PlaceholderFormat set on the builder. The
+// builder itself is not modified.
+func (b DeleteBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return b.Dialect(d).ToSql()
+}
+
+// Compile builds the query once into a CompiledQuery, which binds the values
+// of its Param args on each execution with the Runner set by RunWith.
+func (b DeleteBuilder) Compile() (*CompiledQuery, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return compile(&data, data.RunWith)
+}
+
+func (b DeleteBuilder) statement() Sqlizer {
+	data := builder.GetStruct(b).(deleteData)
+	return &data
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b DeleteBuilder) Prefix(sql string, args ...interface{}) DeleteBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -141,6 +236,38 @@ func (b DeleteBuilder) PrefixExpr(expr Sqlizer) DeleteBuilder {
 	return builder.Append(b, "Prefixes", expr).(DeleteBuilder)
 }
 
+// With adds a common table expression to the WITH clause of the query.
+//
+// See SelectBuilder.With for more information.
+func (b DeleteBuilder) With(name string, as Sqlizer) DeleteBuilder {
+	return builder.Append(b, "CTEs", newCTE(name, as)).(DeleteBuilder)
+}
+
+// WithRecursive adds a recursive common table expression with an optional
+// column list to the query; the clause is then rendered as WITH RECURSIVE.
+func (b DeleteBuilder) WithRecursive(name string, columns []string, as Sqlizer) DeleteBuilder {
+	c := newCTE(name, as)
+	c.columns = columns
+	c.recursive = true
+	return builder.Append(b, "CTEs", c).(DeleteBuilder)
+}
+
+// WithMaterialized adds a common table expression with the MATERIALIZED
+// hint (Postgres 12+) to the query.
+func (b DeleteBuilder) WithMaterialized(name string, as Sqlizer) DeleteBuilder {
+	c := newCTE(name, as)
+	c.materialized = "MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(DeleteBuilder)
+}
+
+// WithNotMaterialized adds a common table expression with the
+// NOT MATERIALIZED hint (Postgres 12+) to the query.
+func (b DeleteBuilder) WithNotMaterialized(name string, as Sqlizer) DeleteBuilder {
+	c := newCTE(name, as)
+	c.materialized = "NOT MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(DeleteBuilder)
+}
+
 // From sets the table to be deleted from.
 func (b DeleteBuilder) From(from string) DeleteBuilder {
 	return builder.Set(b, "From", from).(DeleteBuilder)
@@ -168,6 +295,12 @@ func (b DeleteBuilder) Offset(offset uint64) DeleteBuilder {
 	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(DeleteBuilder)
 }
 
+// Returning adds columns to the RETURNING clause of the query, or to an
+// "OUTPUT DELETED.column" clause with the SQLServer Dialect.
+func (b DeleteBuilder) Returning(columns ...string) DeleteBuilder {
+	return builder.Extend(b, "Returning", columns).(DeleteBuilder)
+}
+
 // Suffix adds an expression to the end of the query
 func (b DeleteBuilder) Suffix(sql string, args ...interface{}) DeleteBuilder {
 	return b.SuffixExpr(Expr(sql, args...))
@@ -178,14 +311,7 @@ func (b DeleteBuilder) SuffixExpr(expr Sqlizer) DeleteBuilder {
 	return builder.Append(b, "Suffixes", expr).(DeleteBuilder)
 }
 
-func (b DeleteBuilder) Query() (*sql.Rows, error) {
-	data := builder.GetStruct(b).(deleteData)
-	return data.Query()
-}
-
-func (d *deleteData) Query() (*sql.Rows, error) {
-	if d.RunWith == nil {
-		return nil, RunnerNotSet
-	}
-	return QueryWith(d.RunWith, d)
+// Comment adds a comment with tags to the query, see SelectBuilder.Comment.
+func (b DeleteBuilder) Comment(tags map[string]string) DeleteBuilder {
+	return builder.Append(b, "Comments", tags).(DeleteBuilder)
 }
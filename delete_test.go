@@ -61,6 +61,15 @@ func TestDeleteBuilderRunners(t *testing.T) {
 
 	b.Exec()
 	assert.Equal(t, expectedSql, db.LastExecSql)
+
+	b.Query()
+	assert.Equal(t, expectedSql, db.LastQuerySql)
+
+	b.QueryRow()
+	assert.Equal(t, expectedSql, db.LastQueryRowSql)
+
+	err := b.Scan()
+	assert.NoError(t, err)
 }
 
 func TestDeleteBuilderNoRunner(t *testing.T) {
@@ -68,6 +77,12 @@ func TestDeleteBuilderNoRunner(t *testing.T) {
 
 	_, err := b.Exec()
 	assert.Equal(t, RunnerNotSet, err)
+
+	_, err = b.Query()
+	assert.Equal(t, RunnerNotSet, err)
+
+	err = b.Scan()
+	assert.Equal(t, RunnerNotSet, err)
 }
 
 func TestDeleteWithQuery(t *testing.T) {
@@ -79,3 +94,18 @@ func TestDeleteWithQuery(t *testing.T) {
 
 	assert.Equal(t, expectedSql, db.LastQuerySql)
 }
+
+func TestDeleteBuilderToSqlFor(t *testing.T) {
+	b := Delete("sessions").Where("id = ?", 1).Limit(1)
+
+	sql, _, err := b.ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM sessions WHERE id = ? LIMIT 1", sql)
+
+	sql, _, err = b.ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE TOP (1) FROM sessions WHERE id = @p1", sql)
+
+	_, _, err = b.ToSqlFor(Postgres)
+	assert.Error(t, err)
+}
@@ -0,0 +1,387 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect describes the SQL syntax of a database. It owns the placeholder
+// format and decides how builders render clauses that differ between
+// databases, e.g. LIMIT vs TOP or RETURNING vs OUTPUT.
+//
+// Set a Dialect with StatementBuilder.Dialect or the Dialect method of a
+// builder. Builders without a Dialect render the portable syntax they always
+// have, with no feature checks.
+//
+// Custom dialects can embed one of the built-in dialects to override a subset
+// of its methods.
+type Dialect interface {
+	// Name returns the name of the database, e.g. "PostgreSQL".
+	Name() string
+
+	// PlaceholderFormat returns the PlaceholderFormat of the database.
+	PlaceholderFormat() PlaceholderFormat
+
+	// QuoteIdent quotes a single identifier, e.g. a table or column name,
+	// for Ident.
+	QuoteIdent(ident string) string
+
+	// Supports reports whether the database supports feature.
+	Supports(feature Feature) bool
+}
+
+// Feature is a piece of SQL syntax that is not supported by every Dialect.
+type Feature int
+
+const (
+	// FeatureCTE is the WITH clause.
+	FeatureCTE Feature = iota
+
+	// FeatureRecursiveKeyword is the RECURSIVE keyword of WITH RECURSIVE.
+	// Dialects without it treat every common table expression as possibly
+	// recursive.
+	FeatureRecursiveKeyword
+
+	// FeatureMaterializedCTE is the [NOT] MATERIALIZED hint of common table
+	// expressions.
+	FeatureMaterializedCTE
+
+	// FeatureLimitOffset is the "LIMIT n OFFSET m" clause.
+	FeatureLimitOffset
+
+	// FeatureOffsetFetch is the "OFFSET m ROWS FETCH NEXT n ROWS ONLY" clause.
+	FeatureOffsetFetch
+
+	// FeatureTop is the "TOP (n)" clause of SELECT, UPDATE and DELETE.
+	FeatureTop
+
+	// FeatureDMLLimit is ORDER BY and LIMIT on UPDATE and DELETE statements.
+	FeatureDMLLimit
+
+	// FeatureLockingClause is the "FOR UPDATE" row locking clause.
+	FeatureLockingClause
+
+	// FeatureLockTableHints is row locking with "WITH (UPDLOCK, ...)" table
+	// hints.
+	FeatureLockTableHints
+
+	// FeatureReturning is the RETURNING clause.
+	FeatureReturning
+
+	// FeatureOutput is the "OUTPUT INSERTED.x" clause.
+	FeatureOutput
+
+	// FeatureOnConflict is the ON CONFLICT clause of INSERT.
+	FeatureOnConflict
+
+	// FeatureOnDuplicateKey is the ON DUPLICATE KEY UPDATE clause of INSERT.
+	FeatureOnDuplicateKey
+
+	// FeatureMerge is the MERGE statement.
+	FeatureMerge
+
+	// FeatureMergeTerminator is the semicolon that must end a MERGE statement.
+	FeatureMergeTerminator
+
+	// FeatureUpdateFrom is the FROM clause of UPDATE.
+	FeatureUpdateFrom
+
+	// FeatureTableAliasAs is the AS keyword between a subquery in FROM or
+	// USING and its alias.
+	FeatureTableAliasAs
+
+	// FeatureNamedArgs is passing sql.NamedArg values for named placeholders,
+	// written as @name with the AtP and :name with the Colon placeholder
+	// format. Without it, Named parameters become positional placeholders.
+	FeatureNamedArgs
+
+	// FeatureLeadingComment is placing the comment of Comment at the start of
+	// the statement, e.g. for proxies that only read leading comments, instead
+	// of at the end.
+	FeatureLeadingComment
+
+	// FeatureIsDistinctFrom is the IS [NOT] DISTINCT FROM comparison.
+	FeatureIsDistinctFrom
+
+	// FeatureNullSafeEqual is the <=> comparison, used for IsDistinctFrom by
+	// dialects without FeatureIsDistinctFrom.
+	FeatureNullSafeEqual
+
+	// FeatureRowValues is comparing row values like "(a, b) > (?, ?)" and
+	// "(a, b) IN ((?,?),(?,?))". Without it, TupleIn and TupleGt are expanded
+	// into OR and AND conditions.
+	FeatureRowValues
+
+	// FeatureArrayLiteral is the "ARRAY[?,?]" literal, used by Any and All
+	// for slices of values.
+	FeatureArrayLiteral
+
+	// FeatureUnorderedOffsetFetch is OFFSET FETCH in a query without ORDER
+	// BY. Dialects without it get "ORDER BY (SELECT NULL)" for such queries.
+	FeatureUnorderedOffsetFetch
+
+	// FeatureBooleanLiterals is the TRUE and FALSE literals of Bool. Without
+	// it, Bool renders 1 and 0.
+	FeatureBooleanLiterals
+
+	// FeatureParenthesizedSelects is parenthesizing the selects of a compound
+	// select, for those with ORDER BY, LIMIT or OFFSET. Without it, they are
+	// wrapped in "SELECT * FROM (...)" instead.
+	FeatureParenthesizedSelects
+
+	// FeatureKeyLocks is the FOR NO KEY UPDATE and FOR KEY SHARE row locking
+	// clauses of LockNoKeyUpdate and LockKeyShare.
+	FeatureKeyLocks
+
+	// FeatureShareLock is the FOR SHARE row locking clause of LockShare.
+	FeatureShareLock
+
+	// FeatureMergeDoNothing is the "THEN DO NOTHING" action of MergeWhen.
+	FeatureMergeDoNothing
+
+	// FeatureMergeConditions is the "AND ..." conditions of MergeWhen.
+	FeatureMergeConditions
+
+	// FeatureMergeDelete is the "THEN DELETE" action of MergeWhen.
+	FeatureMergeDelete
+
+	// FeatureMergeBySource is the "WHEN NOT MATCHED BY SOURCE" clause of
+	// WhenNotMatchedBySource.
+	FeatureMergeBySource
+)
+
+var featureNames = [...]string{
+	FeatureCTE:                  "WITH",
+	FeatureRecursiveKeyword:     "WITH RECURSIVE",
+	FeatureMaterializedCTE:      "MATERIALIZED common table expressions",
+	FeatureLimitOffset:          "LIMIT",
+	FeatureOffsetFetch:          "OFFSET FETCH",
+	FeatureTop:                  "TOP",
+	FeatureDMLLimit:             "ORDER BY and LIMIT in UPDATE and DELETE",
+	FeatureLockingClause:        "row locking clauses",
+	FeatureLockTableHints:       "locking table hints",
+	FeatureReturning:            "RETURNING",
+	FeatureOutput:               "OUTPUT",
+	FeatureOnConflict:           "ON CONFLICT",
+	FeatureOnDuplicateKey:       "ON DUPLICATE KEY UPDATE",
+	FeatureMerge:                "MERGE",
+	FeatureMergeTerminator:      "MERGE terminator",
+	FeatureUpdateFrom:           "UPDATE FROM",
+	FeatureTableAliasAs:         "AS before table aliases",
+	FeatureNamedArgs:            "named args",
+	FeatureLeadingComment:       "leading comments",
+	FeatureIsDistinctFrom:       "IS DISTINCT FROM",
+	FeatureNullSafeEqual:        "<=>",
+	FeatureRowValues:            "row values",
+	FeatureArrayLiteral:         "ARRAY literals",
+	FeatureUnorderedOffsetFetch: "OFFSET FETCH without ORDER BY",
+	FeatureBooleanLiterals:      "boolean literals",
+	FeatureParenthesizedSelects: "parenthesized selects",
+	FeatureKeyLocks:             "FOR NO KEY UPDATE and FOR KEY SHARE",
+	FeatureShareLock:            "FOR SHARE",
+	FeatureMergeDoNothing:       "MERGE DO NOTHING",
+	FeatureMergeConditions:      "MERGE WHEN conditions",
+	FeatureMergeDelete:          "MERGE DELETE",
+	FeatureMergeBySource:        "MERGE WHEN NOT MATCHED BY SOURCE",
+}
+
+func (f Feature) String() string {
+	if f >= 0 && int(f) < len(featureNames) && featureNames[f] != "" {
+		return featureNames[f]
+	}
+	return fmt.Sprintf("Feature(%d)", int(f))
+}
+
+// UnsupportedFeatureError is returned when a statement uses a Feature its
+// Dialect does not support.
+type UnsupportedFeatureError struct {
+	Dialect string
+	Feature Feature
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("%s does not support %s", e.Dialect, e.Feature)
+}
+
+// requireFeature returns an *UnsupportedFeatureError unless d supports f.
+func requireFeature(d Dialect, f Feature) error {
+	if d.Supports(f) {
+		return nil
+	}
+	return &UnsupportedFeatureError{Dialect: d.Name(), Feature: f}
+}
+
+// dialectOrDefault returns d, or the default dialect if d is nil.
+func dialectOrDefault(d Dialect) Dialect {
+	if d == nil {
+		return defaultDialect
+	}
+	return d
+}
+
+type dialect struct {
+	name       string
+	format     PlaceholderFormat
+	quoteOpen  string
+	quoteClose string
+	features   map[Feature]bool
+}
+
+func newDialect(name string, format PlaceholderFormat, quoteOpen, quoteClose string, features ...Feature) *dialect {
+	d := &dialect{
+		name:       name,
+		format:     format,
+		quoteOpen:  quoteOpen,
+		quoteClose: quoteClose,
+		features:   make(map[Feature]bool, len(features)),
+	}
+	for _, f := range features {
+		d.features[f] = true
+	}
+	return d
+}
+
+func (d *dialect) Name() string {
+	return d.name
+}
+
+func (d *dialect) PlaceholderFormat() PlaceholderFormat {
+	return d.format
+}
+
+func (d *dialect) QuoteIdent(ident string) string {
+	return d.quoteOpen + strings.Replace(ident, d.quoteClose, d.quoteClose+d.quoteClose, -1) + d.quoteClose
+}
+
+func (d *dialect) Supports(feature Feature) bool {
+	return d.features[feature]
+}
+
+var (
+	// Postgres is the Dialect of PostgreSQL.
+	Postgres Dialect = newDialect("PostgreSQL", Dollar, `"`, `"`,
+		FeatureCTE, FeatureRecursiveKeyword, FeatureMaterializedCTE,
+		FeatureLimitOffset, FeatureOffsetFetch,
+		FeatureLockingClause, FeatureReturning, FeatureOnConflict,
+		FeatureMerge, FeatureUpdateFrom, FeatureTableAliasAs, FeatureIsDistinctFrom,
+		FeatureRowValues, FeatureArrayLiteral, FeatureUnorderedOffsetFetch, FeatureBooleanLiterals,
+		FeatureParenthesizedSelects, FeatureKeyLocks, FeatureShareLock,
+		FeatureMergeDoNothing, FeatureMergeConditions, FeatureMergeDelete, FeatureMergeBySource)
+
+	// MySQL is the Dialect of MySQL.
+	MySQL Dialect = newDialect("MySQL", Question, "`", "`",
+		FeatureCTE, FeatureRecursiveKeyword,
+		FeatureLimitOffset, FeatureDMLLimit,
+		FeatureLockingClause, FeatureOnDuplicateKey, FeatureTableAliasAs, FeatureNullSafeEqual,
+		FeatureRowValues, FeatureBooleanLiterals, FeatureParenthesizedSelects, FeatureShareLock)
+
+	// SQLite is the Dialect of SQLite.
+	SQLite Dialect = newDialect("SQLite", Question, `"`, `"`,
+		FeatureCTE, FeatureRecursiveKeyword, FeatureMaterializedCTE,
+		FeatureLimitOffset, FeatureDMLLimit,
+		FeatureReturning, FeatureOnConflict, FeatureUpdateFrom, FeatureTableAliasAs,
+		FeatureIsDistinctFrom, FeatureRowValues, FeatureBooleanLiterals)
+
+	// SQLServer is the Dialect of Microsoft SQL Server.
+	SQLServer Dialect = newDialect("SQL Server", AtP, "[", "]",
+		FeatureCTE, FeatureOffsetFetch, FeatureTop,
+		FeatureLockTableHints, FeatureOutput,
+		FeatureMerge, FeatureMergeTerminator, FeatureUpdateFrom, FeatureTableAliasAs,
+		FeatureNamedArgs, FeatureParenthesizedSelects,
+		FeatureMergeConditions, FeatureMergeDelete, FeatureMergeBySource)
+
+	// Oracle is the Dialect of Oracle Database (12c+).
+	Oracle Dialect = newDialect("Oracle", Colon, `"`, `"`,
+		FeatureCTE, FeatureOffsetFetch, FeatureLockingClause, FeatureMerge,
+		FeatureNamedArgs, FeatureUnorderedOffsetFetch, FeatureParenthesizedSelects)
+
+	// defaultDialect renders the syntax of builders without a Dialect.
+	defaultDialect Dialect = newDialect("default", Question, `"`, `"`,
+		FeatureCTE, FeatureRecursiveKeyword, FeatureMaterializedCTE,
+		FeatureLimitOffset, FeatureDMLLimit,
+		FeatureLockingClause, FeatureReturning, FeatureOnConflict, FeatureOnDuplicateKey,
+		FeatureMerge, FeatureUpdateFrom, FeatureTableAliasAs, FeatureIsDistinctFrom,
+		FeatureRowValues, FeatureArrayLiteral, FeatureBooleanLiterals, FeatureParenthesizedSelects,
+		FeatureKeyLocks, FeatureShareLock,
+		FeatureMergeDoNothing, FeatureMergeConditions, FeatureMergeDelete, FeatureMergeBySource)
+)
+
+// limitToSql renders the LIMIT and OFFSET of a statement for dialect d: top is
+// the "TOP (n) " to follow the statement keyword and tail is the clause, with
+// a leading space, for the end of the statement.
+//
+// TOP is only used if topAllowed and the statement has no OFFSET. OFFSET FETCH
+// gets an ORDER BY if the statement is not ordered and d requires one.
+func limitToSql(d Dialect, limit, offset string, ordered, topAllowed bool) (top, tail string, err error) {
+	if len(limit) == 0 && len(offset) == 0 {
+		return
+	}
+
+	switch {
+	case d.Supports(FeatureLimitOffset):
+		if len(limit) > 0 {
+			tail += " LIMIT " + limit
+		}
+		if len(offset) > 0 {
+			tail += " OFFSET " + offset
+		}
+	case topAllowed && len(offset) == 0 && d.Supports(FeatureTop):
+		top = fmt.Sprintf("TOP (%s) ", limit)
+	case d.Supports(FeatureOffsetFetch):
+		if len(offset) == 0 {
+			offset = "0"
+		}
+		if !ordered && !d.Supports(FeatureUnorderedOffsetFetch) {
+			tail = " ORDER BY (SELECT NULL)"
+		}
+		tail += fmt.Sprintf(" OFFSET %s ROWS", offset)
+		if len(limit) > 0 {
+			tail += fmt.Sprintf(" FETCH NEXT %s ROWS ONLY", limit)
+		}
+	default:
+		err = requireFeature(d, FeatureLimitOffset)
+	}
+	return
+}
+
+// dmlLimitToSql renders ORDER BY, LIMIT and OFFSET of UPDATE and DELETE
+// statements like limitToSql, falling back to TOP for a bare LIMIT.
+func dmlLimitToSql(d Dialect, orderBys []string, limit, offset string) (top, tail string, err error) {
+	if len(orderBys) == 0 && len(limit) == 0 && len(offset) == 0 {
+		return
+	}
+
+	if !d.Supports(FeatureDMLLimit) {
+		if len(orderBys) == 0 && len(offset) == 0 && d.Supports(FeatureTop) {
+			top = fmt.Sprintf("TOP (%s) ", limit)
+			return
+		}
+		err = requireFeature(d, FeatureDMLLimit)
+		return
+	}
+
+	if len(orderBys) > 0 {
+		tail = " ORDER BY " + strings.Join(orderBys, ", ")
+	}
+	if len(limit) > 0 {
+		tail += " LIMIT " + limit
+	}
+	if len(offset) > 0 {
+		tail += " OFFSET " + offset
+	}
+	return
+}
+
+// writeTableExpr writes a table or a subquery aliased with Alias for use in
+// FROM or USING, without the AS keyword if dialect d does not allow it there.
+func writeTableExpr(w *SqlWriter, d Dialect, table Sqlizer) error {
+	if alias, ok := table.(aliasExpr); ok && !d.Supports(FeatureTableAliasAs) {
+		if err := w.writeParenthesized(alias.expr); err != nil {
+			return err
+		}
+		w.WriteString(" ")
+		w.WriteString(alias.alias)
+		return nil
+	}
+	return w.WriteSqlizer(table)
+}
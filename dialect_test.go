@@ -0,0 +1,159 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectQuoteIdent(t *testing.T) {
+	assert.Equal(t, `"a""b"`, Postgres.QuoteIdent(`a"b`))
+	assert.Equal(t, "`a``b`", MySQL.QuoteIdent("a`b"))
+	assert.Equal(t, "[a]]b]", SQLServer.QuoteIdent("a]b"))
+}
+
+func TestDialectIdentAndBool(t *testing.T) {
+	b := Select().Column(Ident("order")).From("t").Where(Eq{"active": Bool(true)}).Where(Eq{"deleted": Bool(false)})
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT "order" FROM t WHERE active = TRUE AND deleted = FALSE`, sql)
+	assert.Empty(t, args)
+
+	sql, _, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT `order` FROM t WHERE active = TRUE AND deleted = FALSE", sql)
+
+	sql, _, err = b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT [order] FROM t WHERE active = 1 AND deleted = 0", sql)
+
+	sql, _, err = Update("users").Set("active", Bool(true)).ToSqlFor(Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET active = 1", sql)
+}
+
+func TestDialectOffsetFetchOrderBy(t *testing.T) {
+	sql, _, err := Select("*").From("t").Offset(20).Limit(10).ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+
+	sql, _, err = Union(Select("a").From("t1"), Select("a").From("t2")).Offset(5).ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t1 UNION SELECT a FROM t2 ORDER BY (SELECT NULL) OFFSET 5 ROWS", sql)
+
+	sql, _, err = Select("*").From("t").Offset(20).Limit(10).ToSqlFor(Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+}
+
+func TestStatementBuilderDialect(t *testing.T) {
+	sb := StatementBuilder.Dialect(Oracle)
+
+	sql, args, err := sb.Select("*").From("t").Where("a = ?", 1).Limit(10).Offset(20).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = :1 OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _, err = sb.Select("*").From("t").Limit(10).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+}
+
+func TestDialectSelectLimit(t *testing.T) {
+	b := Select("*").Distinct().From("t").OrderBy("id").Limit(10)
+
+	sql, _, err := b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT * FROM t ORDER BY id LIMIT 10", sql)
+
+	sql, _, err = b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT TOP (10) * FROM t ORDER BY id", sql)
+
+	sql, _, err = b.Offset(20).Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT * FROM t ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+}
+
+func TestDialectCompoundLimit(t *testing.T) {
+	sql, _, err := Union(Select("a").From("t1"), Select("a").From("t2")).
+		OrderBy("a").
+		Limit(5).
+		Dialect(SQLServer).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t1 UNION SELECT a FROM t2 ORDER BY a OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY", sql)
+}
+
+func TestDialectDMLLimit(t *testing.T) {
+	sql, _, err := Update("t").Set("a", 1).Limit(10).Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE TOP (10) t SET a = @p1", sql)
+
+	sql, _, err = Delete("t").Where("a = ?", 1).Limit(10).Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE TOP (10) FROM t WHERE a = @p1", sql)
+
+	sql, _, err = Delete("t").OrderBy("id").Limit(10).Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t ORDER BY id LIMIT 10", sql)
+
+	_, _, err = Delete("t").OrderBy("id").Limit(10).Dialect(SQLServer).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "SQL Server", Feature: FeatureDMLLimit}, err)
+
+	_, _, err = Update("t").Set("a", 1).Limit(10).Dialect(Postgres).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "PostgreSQL", Feature: FeatureDMLLimit}, err)
+}
+
+func TestDialectTableAlias(t *testing.T) {
+	sql, args, err := Select("s.id").
+		FromSelect(Select("id").From("t").Where("a = ?", 1), "s").
+		Dialect(Oracle).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT s.id FROM (SELECT id FROM t WHERE a = :1) s", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestDialectCTE(t *testing.T) {
+	sql, _, err := Select("*").
+		WithRecursive("r", []string{"n"}, Expr("SELECT 1")).
+		From("r").
+		Dialect(SQLServer).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH r(n) AS (SELECT 1) SELECT * FROM r", sql)
+
+	_, _, err = Select("*").WithMaterialized("c", Expr("SELECT 1")).From("c").Dialect(MySQL).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "MySQL", Feature: FeatureMaterializedCTE}, err)
+}
+
+func TestDialectUnsupportedFeatures(t *testing.T) {
+	_, _, err := Insert("t").Columns("a").Values(1).OnConflict("a").DoNothing().Dialect(MySQL).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "MySQL", Feature: FeatureOnConflict}, err)
+
+	_, _, err = Insert("t").Columns("a").Values(1).OnDuplicateKeyUpdate("a", 2).Dialect(Postgres).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "PostgreSQL", Feature: FeatureOnDuplicateKey}, err)
+
+	_, _, err = Insert("t").Columns("a").Values(1).Returning("id").Dialect(MySQL).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "MySQL", Feature: FeatureReturning}, err)
+
+	_, _, err = Update("t").Set("a", 1).From("u").Dialect(MySQL).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "MySQL", Feature: FeatureUpdateFrom}, err)
+
+	_, _, err = Merge("t").Using("s").On("t.id = s.id").When(WhenMatched().Delete()).Dialect(SQLite).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "SQLite", Feature: FeatureMerge}, err)
+	assert.EqualError(t, err, "SQLite does not support MERGE")
+}
+
+func TestDialectMergeTerminator(t *testing.T) {
+	sql, _, err := Merge("t").
+		Using("s").
+		On("t.id = s.id").
+		When(WhenMatched().Delete()).
+		Dialect(SQLServer).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED THEN DELETE;", sql)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
-	"strings"
 )
 
 const (
@@ -29,77 +28,91 @@ func Expr(sql string, args ...interface{}) Sqlizer {
 }
 
 func (e expr) ToSql() (sql string, args []interface{}, err error) {
-	simple := true
+	if e.simple() {
+		return e.sql, e.args, nil
+	}
+	return renderToSql(e)
+}
+
+// simple reports whether e has no Sqlizer or Named args to expand.
+func (e expr) simple() bool {
+	if _, ok := namedArgs(e.args); ok {
+		return false
+	}
 	for _, arg := range e.args {
 		if _, ok := arg.(Sqlizer); ok {
-			simple = false
+			return false
 		}
 	}
-	if simple {
-		return e.sql, e.args, nil
+	return true
+}
+
+func (e expr) RenderSql(w *SqlWriter) error {
+	if e.simple() {
+		w.WriteString(e.sql)
+		w.AddArgs(e.args...)
+		return nil
+	}
+	if names, ok := namedArgs(e.args); ok {
+		return writeNamed(w, e.sql, names)
 	}
 
 	buf := &bytes.Buffer{}
 	ap := e.args
-	sp := e.sql
 
-	var isql string
-	var iargs []interface{}
-
-	for err == nil && len(ap) > 0 && len(sp) > 0 {
-		i := strings.Index(sp, "?")
-		if i < 0 {
-			// no more placeholders
-			break
-		}
-		if len(sp) > i+1 && sp[i+1:i+2] == "?" {
-			// escaped "??"; append it and step past
-			buf.WriteString(sp[:i+2])
-			sp = sp[i+2:]
-			continue
+	err := scanPlaceholders(buf, e.sql, true, func(buf *bytes.Buffer) error {
+		if len(ap) == 0 {
+			buf.WriteString("?")
+			return nil
 		}
 
 		if as, ok := ap[0].(Sqlizer); ok {
-			// sqlizer argument; expand it and append the result
-			isql, iargs, err = as.ToSql()
-			buf.WriteString(sp[:i])
-			buf.WriteString(isql)
-			args = append(args, iargs...)
+			// sqlizer argument; expand it in place
+			w.WriteString(buf.String())
+			buf.Reset()
+			if err := w.WriteSqlizer(as); err != nil {
+				return err
+			}
 		} else {
 			// normal argument; append it and the placeholder
-			buf.WriteString(sp[:i+1])
-			args = append(args, ap[0])
+			buf.WriteString("?")
+			w.AddArgs(ap[0])
 		}
 
-		// step past the argument and placeholder
+		// step past the argument
 		ap = ap[1:]
-		sp = sp[i+1:]
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// append the remaining sql and arguments
-	buf.WriteString(sp)
-	return buf.String(), append(args, ap...), err
+	w.WriteString(buf.String())
+	w.AddArgs(ap...)
+	return nil
 }
 
 type concatExpr []interface{}
 
 func (ce concatExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(ce)
+}
+
+func (ce concatExpr) RenderSql(w *SqlWriter) error {
 	for _, part := range ce {
 		switch p := part.(type) {
 		case string:
-			sql += p
+			w.WriteString(p)
 		case Sqlizer:
-			pSql, pArgs, err := p.ToSql()
-			if err != nil {
-				return "", nil, err
+			if err := w.WriteSqlizer(p); err != nil {
+				return err
 			}
-			sql += pSql
-			args = append(args, pArgs...)
 		default:
-			return "", nil, fmt.Errorf("%#v is not a string or Sqlizer", part)
+			return fmt.Errorf("%#v is not a string or Sqlizer", part)
 		}
 	}
-	return
+	return nil
 }
 
 // ConcatExpr builds an expression by concatenating strings and other expressions.
@@ -126,25 +139,85 @@ func Alias(expr Sqlizer, alias string) aliasExpr {
 }
 
 func (e aliasExpr) ToSql() (sql string, args []interface{}, err error) {
-	sql, args, err = e.expr.ToSql()
-	if err == nil {
-		sql = fmt.Sprintf("(%s) AS %s", sql, e.alias)
+	return renderToSql(e)
+}
+
+func (e aliasExpr) RenderSql(w *SqlWriter) error {
+	if err := w.writeParenthesized(e.expr); err != nil {
+		return err
 	}
-	return
+	w.WriteString(" AS ")
+	w.WriteString(e.alias)
+	return nil
+}
+
+type identExpr string
+
+// Ident builds an identifier, e.g. a table or column name, quoted with the
+// QuoteIdent of the Dialect of the statement.
+//
+// Ex:
+//     Select().Column(Ident("order")).From("t").Dialect(SQLServer)
+//     == "SELECT [order] FROM t"
+func Ident(name string) Sqlizer {
+	return identExpr(name)
+}
+
+func (e identExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e identExpr) RenderSql(w *SqlWriter) error {
+	w.WriteString(w.statementDialect(nil).QuoteIdent(string(e)))
+	return nil
+}
+
+type boolExpr bool
+
+// Bool builds the boolean literal TRUE or FALSE, or 1 or 0 in dialects
+// without FeatureBooleanLiterals.
+//
+// Ex:
+//     Update("users").Set("active", Bool(true)).Dialect(SQLServer)
+//     == "UPDATE users SET active = 1"
+func Bool(v bool) Sqlizer {
+	return boolExpr(v)
+}
+
+func (e boolExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e boolExpr) RenderSql(w *SqlWriter) error {
+	literals := [2]string{"FALSE", "TRUE"}
+	if !w.statementDialect(nil).Supports(FeatureBooleanLiterals) {
+		literals = [2]string{"0", "1"}
+	}
+	if e {
+		w.WriteString(literals[1])
+	} else {
+		w.WriteString(literals[0])
+	}
+	return nil
 }
 
 // Eq is syntactic sugar for use with Where/Having/Set methods.
+//
+// Sqlizer values are rendered in place of the placeholder, and statements
+// like SelectBuilder in parentheses.
+// Ex:
+//     .Where(Eq{"owner_id": Select("id").From("users").Where("name = ?", name)})
+//     == "owner_id = (SELECT id FROM users WHERE name = ?)"
 type Eq map[string]interface{}
 
-func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
+func (eq Eq) render(w *SqlWriter, useNotOpr bool) error {
 	if len(eq) == 0 {
 		// Empty Sql{} evaluates to true.
-		sql = sqlTrue
-		return
+		w.WriteString(sqlTrue)
+		return nil
 	}
 
 	var (
-		exprs       []string
 		equalOpr    = "="
 		inOpr       = "IN"
 		nullOpr     = "IS"
@@ -159,14 +232,23 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 	}
 
 	sortedKeys := getSortedKeys(eq)
-	for _, key := range sortedKeys {
-		var expr string
+	for i, key := range sortedKeys {
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
 		val := eq[key]
 
 		switch v := val.(type) {
+		case Sqlizer:
+			fmt.Fprintf(w, "%s %s ", key, equalOpr)
+			if err := writeOperand(w, v); err != nil {
+				return err
+			}
+			continue
 		case driver.Valuer:
+			var err error
 			if val, err = v.Value(); err != nil {
-				return
+				return err
 			}
 		}
 
@@ -180,34 +262,34 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 		}
 
 		if val == nil {
-			expr = fmt.Sprintf("%s %s NULL", key, nullOpr)
+			fmt.Fprintf(w, "%s %s NULL", key, nullOpr)
 		} else {
 			if isListType(val) {
 				valVal := reflect.ValueOf(val)
 				if valVal.Len() == 0 {
-					expr = inEmptyExpr
-					if args == nil {
-						args = []interface{}{}
-					}
+					w.WriteString(inEmptyExpr)
+					w.AddArgs([]interface{}{}...)
 				} else {
 					for i := 0; i < valVal.Len(); i++ {
-						args = append(args, valVal.Index(i).Interface())
+						w.AddArgs(valVal.Index(i).Interface())
 					}
-					expr = fmt.Sprintf("%s %s (%s)", key, inOpr, Placeholders(valVal.Len()))
+					fmt.Fprintf(w, "%s %s (%s)", key, inOpr, Placeholders(valVal.Len()))
 				}
 			} else {
-				expr = fmt.Sprintf("%s %s ?", key, equalOpr)
-				args = append(args, val)
+				fmt.Fprintf(w, "%s %s ", key, equalOpr)
+				w.WritePlaceholder(val)
 			}
 		}
-		exprs = append(exprs, expr)
 	}
-	sql = strings.Join(exprs, " AND ")
-	return
+	return nil
 }
 
 func (eq Eq) ToSql() (sql string, args []interface{}, err error) {
-	return eq.toSQL(false)
+	return renderToSql(eq)
+}
+
+func (eq Eq) RenderSql(w *SqlWriter) error {
+	return eq.render(w, false)
 }
 
 // NotEq is syntactic sugar for use with Where/Having/Set methods.
@@ -216,7 +298,11 @@ func (eq Eq) ToSql() (sql string, args []interface{}, err error) {
 type NotEq Eq
 
 func (neq NotEq) ToSql() (sql string, args []interface{}, err error) {
-	return Eq(neq).toSQL(true)
+	return renderToSql(neq)
+}
+
+func (neq NotEq) RenderSql(w *SqlWriter) error {
+	return Eq(neq).render(w, true)
 }
 
 // Like is syntactic sugar for use with LIKE conditions.
@@ -224,38 +310,46 @@ func (neq NotEq) ToSql() (sql string, args []interface{}, err error) {
 //     .Where(Like{"name": "%irrel"})
 type Like map[string]interface{}
 
-func (lk Like) toSql(opr string) (sql string, args []interface{}, err error) {
-	var exprs []string
+func (lk Like) render(w *SqlWriter, opr string) error {
+	i := 0
 	for key, val := range lk {
-		expr := ""
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+		i++
 
 		switch v := val.(type) {
+		case Sqlizer:
+			fmt.Fprintf(w, "%s %s ", key, opr)
+			if err := writeOperand(w, v); err != nil {
+				return err
+			}
+			continue
 		case driver.Valuer:
+			var err error
 			if val, err = v.Value(); err != nil {
-				return
+				return err
 			}
 		}
 
 		if val == nil {
-			err = fmt.Errorf("cannot use null with like operators")
-			return
-		} else {
-			if isListType(val) {
-				err = fmt.Errorf("cannot use array or slice with like operators")
-				return
-			} else {
-				expr = fmt.Sprintf("%s %s ?", key, opr)
-				args = append(args, val)
-			}
+			return fmt.Errorf("cannot use null with like operators")
 		}
-		exprs = append(exprs, expr)
+		if isListType(val) {
+			return fmt.Errorf("cannot use array or slice with like operators")
+		}
+		fmt.Fprintf(w, "%s %s ", key, opr)
+		w.WritePlaceholder(val)
 	}
-	sql = strings.Join(exprs, " AND ")
-	return
+	return nil
 }
 
 func (lk Like) ToSql() (sql string, args []interface{}, err error) {
-	return lk.toSql("LIKE")
+	return renderToSql(lk)
+}
+
+func (lk Like) RenderSql(w *SqlWriter) error {
+	return lk.render(w, "LIKE")
 }
 
 // NotLike is syntactic sugar for use with LIKE conditions.
@@ -264,7 +358,11 @@ func (lk Like) ToSql() (sql string, args []interface{}, err error) {
 type NotLike Like
 
 func (nlk NotLike) ToSql() (sql string, args []interface{}, err error) {
-	return Like(nlk).toSql("NOT LIKE")
+	return renderToSql(nlk)
+}
+
+func (nlk NotLike) RenderSql(w *SqlWriter) error {
+	return Like(nlk).render(w, "NOT LIKE")
 }
 
 // ILike is syntactic sugar for use with ILIKE conditions.
@@ -273,7 +371,11 @@ func (nlk NotLike) ToSql() (sql string, args []interface{}, err error) {
 type ILike Like
 
 func (ilk ILike) ToSql() (sql string, args []interface{}, err error) {
-	return Like(ilk).toSql("ILIKE")
+	return renderToSql(ilk)
+}
+
+func (ilk ILike) RenderSql(w *SqlWriter) error {
+	return Like(ilk).render(w, "ILIKE")
 }
 
 // NotILike is syntactic sugar for use with ILIKE conditions.
@@ -282,19 +384,23 @@ func (ilk ILike) ToSql() (sql string, args []interface{}, err error) {
 type NotILike Like
 
 func (nilk NotILike) ToSql() (sql string, args []interface{}, err error) {
-	return Like(nilk).toSql("NOT ILIKE")
+	return renderToSql(nilk)
+}
+
+func (nilk NotILike) RenderSql(w *SqlWriter) error {
+	return Like(nilk).render(w, "NOT ILIKE")
 }
 
 // Lt is syntactic sugar for use with Where/Having/Set methods.
+//
+// Sqlizer values are rendered like in Eq.
 // Ex:
 //     .Where(Lt{"id": 1})
+//     .Where(Lt{"expires_at": Expr("CURRENT_TIMESTAMP")})
 type Lt map[string]interface{}
 
-func (lt Lt) toSql(opposite, orEq bool) (sql string, args []interface{}, err error) {
-	var (
-		exprs []string
-		opr   = "<"
-	)
+func (lt Lt) render(w *SqlWriter, opposite, orEq bool) error {
+	opr := "<"
 
 	if opposite {
 		opr = ">"
@@ -305,36 +411,44 @@ func (lt Lt) toSql(opposite, orEq bool) (sql string, args []interface{}, err err
 	}
 
 	sortedKeys := getSortedKeys(lt)
-	for _, key := range sortedKeys {
-		var expr string
+	for i, key := range sortedKeys {
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
 		val := lt[key]
 
 		switch v := val.(type) {
+		case Sqlizer:
+			fmt.Fprintf(w, "%s %s ", key, opr)
+			if err := writeOperand(w, v); err != nil {
+				return err
+			}
+			continue
 		case driver.Valuer:
+			var err error
 			if val, err = v.Value(); err != nil {
-				return
+				return err
 			}
 		}
 
 		if val == nil {
-			err = fmt.Errorf("cannot use null with less than or greater than operators")
-			return
+			return fmt.Errorf("cannot use null with less than or greater than operators")
 		}
 		if isListType(val) {
-			err = fmt.Errorf("cannot use array or slice with less than or greater than operators")
-			return
+			return fmt.Errorf("cannot use array or slice with less than or greater than operators")
 		}
-		expr = fmt.Sprintf("%s %s ?", key, opr)
-		args = append(args, val)
-
-		exprs = append(exprs, expr)
+		fmt.Fprintf(w, "%s %s ", key, opr)
+		w.WritePlaceholder(val)
 	}
-	sql = strings.Join(exprs, " AND ")
-	return
+	return nil
 }
 
 func (lt Lt) ToSql() (sql string, args []interface{}, err error) {
-	return lt.toSql(false, false)
+	return renderToSql(lt)
+}
+
+func (lt Lt) RenderSql(w *SqlWriter) error {
+	return lt.render(w, false, false)
 }
 
 // LtOrEq is syntactic sugar for use with Where/Having/Set methods.
@@ -343,7 +457,11 @@ func (lt Lt) ToSql() (sql string, args []interface{}, err error) {
 type LtOrEq Lt
 
 func (ltOrEq LtOrEq) ToSql() (sql string, args []interface{}, err error) {
-	return Lt(ltOrEq).toSql(false, true)
+	return renderToSql(ltOrEq)
+}
+
+func (ltOrEq LtOrEq) RenderSql(w *SqlWriter) error {
+	return Lt(ltOrEq).render(w, false, true)
 }
 
 // Gt is syntactic sugar for use with Where/Having/Set methods.
@@ -352,7 +470,11 @@ func (ltOrEq LtOrEq) ToSql() (sql string, args []interface{}, err error) {
 type Gt Lt
 
 func (gt Gt) ToSql() (sql string, args []interface{}, err error) {
-	return Lt(gt).toSql(true, false)
+	return renderToSql(gt)
+}
+
+func (gt Gt) RenderSql(w *SqlWriter) error {
+	return Lt(gt).render(w, true, false)
 }
 
 // GtOrEq is syntactic sugar for use with Where/Having/Set methods.
@@ -361,44 +483,359 @@ func (gt Gt) ToSql() (sql string, args []interface{}, err error) {
 type GtOrEq Lt
 
 func (gtOrEq GtOrEq) ToSql() (sql string, args []interface{}, err error) {
-	return Lt(gtOrEq).toSql(true, true)
+	return renderToSql(gtOrEq)
 }
 
-type conj []Sqlizer
+func (gtOrEq GtOrEq) RenderSql(w *SqlWriter) error {
+	return Lt(gtOrEq).render(w, true, true)
+}
 
-func (c conj) join(sep, defaultExpr string) (sql string, args []interface{}, err error) {
-	if len(c) == 0 {
-		return defaultExpr, []interface{}{}, nil
-	}
-	var sqlParts []string
-	for _, sqlizer := range c {
-		partSQL, partArgs, err := nestedToSql(sqlizer)
+// Between is syntactic sugar for use with Where/Having methods. Each value
+// is a [2]interface{} of the lower and upper bound, which may be Sqlizers
+// rendered like in Eq.
+// Ex:
+//     .Where(Between{"created_at": [2]interface{}{from, to}})
+//     == "created_at BETWEEN ? AND ?"
+type Between map[string]interface{}
+
+func (bt Between) render(w *SqlWriter, opr string) error {
+	sortedKeys := getSortedKeys(bt)
+	for i, key := range sortedKeys {
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+
+		bounds := reflect.ValueOf(bt[key])
+		if (bounds.Kind() != reflect.Array && bounds.Kind() != reflect.Slice) || bounds.Len() != 2 {
+			return fmt.Errorf("between operators need two bounds, not %T", bt[key])
+		}
+
+		lo, err := boundValue(bounds.Index(0).Interface())
+		if err != nil {
+			return err
+		}
+		hi, err := boundValue(bounds.Index(1).Interface())
 		if err != nil {
-			return "", nil, err
+			return err
+		}
+		if lo == nil || hi == nil {
+			return fmt.Errorf("cannot use null with between operators")
+		}
+
+		fmt.Fprintf(w, "%s %s ", key, opr)
+		if err := writeValue(w, lo); err != nil {
+			return err
+		}
+		w.WriteString(" AND ")
+		if err := writeValue(w, hi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bt Between) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(bt)
+}
+
+func (bt Between) RenderSql(w *SqlWriter) error {
+	return bt.render(w, "BETWEEN")
+}
+
+// NotBetween is syntactic sugar for use with Where/Having methods.
+// Ex:
+//     .Where(NotBetween{"age": [2]interface{}{18, 65}}) == "age NOT BETWEEN 18 AND 65"
+type NotBetween Between
+
+func (nbt NotBetween) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(nbt)
+}
+
+func (nbt NotBetween) RenderSql(w *SqlWriter) error {
+	return Between(nbt).render(w, "NOT BETWEEN")
+}
+
+type rangeExpr struct {
+	column string
+	lo, hi interface{}
+}
+
+// Range builds the condition of the half-open range [lo, hi) of column,
+// "column >= lo AND column < hi". Bounds that are nil, nil pointers or
+// driver.Valuers with a nil value, like an invalid sql.NullTime, are open and
+// left out. A range without bounds is always true.
+// Ex:
+//     .Where(Range("created_at", from, nil)) == "created_at >= ?"
+func Range(column string, lo, hi interface{}) Sqlizer {
+	return rangeExpr{column: column, lo: lo, hi: hi}
+}
+
+func (e rangeExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e rangeExpr) RenderSql(w *SqlWriter) error {
+	lo, err := boundValue(e.lo)
+	if err != nil {
+		return err
+	}
+	hi, err := boundValue(e.hi)
+	if err != nil {
+		return err
+	}
+
+	if lo == nil && hi == nil {
+		w.WriteString(sqlTrue)
+		return nil
+	}
+	if lo != nil {
+		fmt.Fprintf(w, "%s >= ", e.column)
+		if err := writeValue(w, lo); err != nil {
+			return err
 		}
-		if partSQL != "" {
-			sqlParts = append(sqlParts, partSQL)
-			args = append(args, partArgs...)
+	}
+	if hi != nil {
+		if lo != nil {
+			w.WriteString(" AND ")
+		}
+		fmt.Fprintf(w, "%s < ", e.column)
+		if err := writeValue(w, hi); err != nil {
+			return err
 		}
 	}
-	if len(sqlParts) > 0 {
-		sql = fmt.Sprintf("(%s)", strings.Join(sqlParts, sep))
+	return nil
+}
+
+// boundValue returns the value of a bound of Between or Range, with
+// driver.Valuers unwrapped, or nil if it is nil or a nil pointer.
+func boundValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case Sqlizer:
+		return v, nil
+	case driver.Valuer:
+		var err error
+		if val, err = v.Value(); err != nil {
+			return nil, err
+		}
 	}
-	return
+
+	if r := reflect.ValueOf(val); r.Kind() == reflect.Ptr && r.IsNil() {
+		return nil, nil
+	}
+	return val, nil
+}
+
+// writeValue writes the value of a condition, which may be a Sqlizer.
+func writeValue(w *SqlWriter, val interface{}) error {
+	if s, ok := val.(Sqlizer); ok {
+		return writeOperand(w, s)
+	}
+	w.WritePlaceholder(val)
+	return nil
+}
+
+// writeOperand writes the Sqlizer value of a condition, in parentheses if it
+// is a statement like SelectBuilder.
+func writeOperand(w *SqlWriter, s Sqlizer) error {
+	if isStatement(s) {
+		return w.writeParenthesized(s)
+	}
+	return w.WriteSqlizer(s)
+}
+
+// isStatement reports whether s is a statement builder or its data.
+func isStatement(s Sqlizer) bool {
+	_, ok := statementOf(s).(hookedStatement)
+	return ok
+}
+
+type conj []Sqlizer
+
+func (c conj) join(w *SqlWriter, sep, defaultExpr string) error {
+	if len(c) == 0 {
+		w.WriteString(defaultExpr)
+		w.AddArgs([]interface{}{}...)
+		return nil
+	}
+
+	parts := w.sub()
+	if err := parts.writeSqlizers(c, sep); err != nil {
+		return err
+	}
+	if parts.Len() > 0 {
+		w.WriteString("(")
+		w.append(parts)
+		w.WriteString(")")
+	}
+	return nil
 }
 
 // And conjunction Sqlizers
 type And conj
 
 func (a And) ToSql() (string, []interface{}, error) {
-	return conj(a).join(" AND ", sqlTrue)
+	return renderToSql(a)
+}
+
+func (a And) RenderSql(w *SqlWriter) error {
+	return conj(a).join(w, " AND ", sqlTrue)
 }
 
 // Or conjunction Sqlizers
 type Or conj
 
 func (o Or) ToSql() (string, []interface{}, error) {
-	return conj(o).join(" OR ", sqlFalse)
+	return renderToSql(o)
+}
+
+func (o Or) RenderSql(w *SqlWriter) error {
+	return conj(o).join(w, " OR ", sqlFalse)
+}
+
+type notExpr struct {
+	cond Sqlizer
+}
+
+// Not negates the condition cond, e.g. an Or or a Like, in parentheses.
+// Always true and always false conditions, like an empty Eq or Or, become
+// always false and always true.
+// Ex:
+//     .Where(Not(Or{Eq{"a": 1}, Like{"b": "x%"}})) == "NOT (a = ? OR b LIKE ?)"
+func Not(cond Sqlizer) Sqlizer {
+	return notExpr{cond: cond}
+}
+
+func (e notExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e notExpr) RenderSql(w *SqlWriter) error {
+	cond := w.sub()
+	if err := cond.WriteSqlizer(e.cond); err != nil {
+		return err
+	}
+
+	switch sql := cond.String(); {
+	case sql == "":
+		return nil
+	case sql == sqlTrue:
+		w.WriteString(sqlFalse)
+	case sql == sqlFalse:
+		w.WriteString(sqlTrue)
+	case parenthesized(sql):
+		w.WriteString("NOT ")
+		w.append(cond)
+	default:
+		w.WriteString("NOT (")
+		w.append(cond)
+		w.WriteString(")")
+	}
+	return nil
+}
+
+// parenthesized reports whether sql is enclosed in a single pair of
+// parentheses.
+func parenthesized(sql string) bool {
+	if len(sql) < 2 || sql[0] != '(' || sql[len(sql)-1] != ')' {
+		return false
+	}
+
+	depth := 0
+	for i := 0; i < len(sql); i++ {
+		if end := skippedEnd(sql, i); end > i {
+			i = end - 1
+			continue
+		}
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(sql)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// IsDistinctFrom is syntactic sugar for use with Where/Having methods, for
+// comparisons where NULL equals NULL. Dialects without FeatureIsDistinctFrom
+// use the <=> operator of FeatureNullSafeEqual.
+// Ex:
+//     .Where(IsDistinctFrom{"a": nil}) == "a IS DISTINCT FROM NULL"
+//     MySQL: "NOT (a <=> NULL)"
+type IsDistinctFrom map[string]interface{}
+
+func (df IsDistinctFrom) render(w *SqlWriter, not bool) error {
+	dialect := w.statementDialect(nil)
+	nullSafeEqual := !dialect.Supports(FeatureIsDistinctFrom)
+	if nullSafeEqual && !dialect.Supports(FeatureNullSafeEqual) {
+		return requireFeature(dialect, FeatureIsDistinctFrom)
+	}
+
+	opr := "IS DISTINCT FROM"
+	if not {
+		opr = "IS NOT DISTINCT FROM"
+	}
+
+	sortedKeys := getSortedKeys(df)
+	for i, key := range sortedKeys {
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+
+		val, err := boundValue(df[key])
+		if err != nil {
+			return err
+		}
+		if val != nil && isListType(val) {
+			return fmt.Errorf("cannot use array or slice with distinct operators")
+		}
+
+		switch {
+		case !nullSafeEqual:
+			fmt.Fprintf(w, "%s %s ", key, opr)
+		case not:
+			fmt.Fprintf(w, "%s <=> ", key)
+		default:
+			fmt.Fprintf(w, "NOT (%s <=> ", key)
+		}
+
+		if val == nil {
+			w.WriteString("NULL")
+		} else if err := writeValue(w, val); err != nil {
+			return err
+		}
+
+		if nullSafeEqual && !not {
+			w.WriteString(")")
+		}
+	}
+	return nil
+}
+
+func (df IsDistinctFrom) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(df)
+}
+
+func (df IsDistinctFrom) RenderSql(w *SqlWriter) error {
+	return df.render(w, false)
+}
+
+// IsNotDistinctFrom is syntactic sugar for use with Where/Having methods, see
+// IsDistinctFrom.
+// Ex:
+//     .Where(IsNotDistinctFrom{"a": 1}) == "a IS NOT DISTINCT FROM ?"
+//     MySQL: "a <=> ?"
+type IsNotDistinctFrom IsDistinctFrom
+
+func (ndf IsNotDistinctFrom) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(ndf)
+}
+
+func (ndf IsNotDistinctFrom) RenderSql(w *SqlWriter) error {
+	return IsDistinctFrom(ndf).render(w, true)
 }
 
 func getSortedKeys(exp map[string]interface{}) []string {
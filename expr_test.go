@@ -167,6 +167,168 @@ func TestGtOrEqToSql(t *testing.T) {
 	assert.Equal(t, expectedArgs, args)
 }
 
+func TestEqSqlizerToSql(t *testing.T) {
+	b := Eq{
+		"owner_id":   Select("id").From("users").Where("name = ?", "moe"),
+		"updated_at": Expr("NOW()"),
+		"id":         1,
+	}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "id = ? AND owner_id = (SELECT id FROM users WHERE name = ?) AND updated_at = NOW()"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{1, "moe"}
+	assert.Equal(t, expectedArgs, args)
+
+	sql, _, err = NotEq{"a": Expr("b + ?", 1)}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a <> b + ?", sql)
+}
+
+func TestLtSqlizerToSql(t *testing.T) {
+	sql, args, err := Gt{"expires_at": Expr("CURRENT_TIMESTAMP")}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "expires_at > CURRENT_TIMESTAMP", sql)
+	assert.Nil(t, args)
+
+	sql, args, err = LtOrEq{"price": Select("avg(price)").From("items").Where("kind = ?", 2)}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "price <= (SELECT avg(price) FROM items WHERE kind = ?)", sql)
+	assert.Equal(t, []interface{}{2}, args)
+
+	sql, _, err = Like{"name": ConcatExpr("prefix || ", Expr("?", "%"))}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "name LIKE prefix || ?", sql)
+}
+
+func TestSqlizerValuesNumberedOnce(t *testing.T) {
+	sub := Select("id").From("users").Where("name = ?", "moe").PlaceholderFormat(Dollar)
+	sql, args, err := Select("*").From("items").
+		Where("kind = ?", 1).
+		Where(Eq{"owner_id": sub}).
+		Where(Lt{"created_at": Expr("now() - ?::interval", "1 day")}).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM items WHERE kind = $1 AND owner_id = (SELECT id FROM users WHERE name = $2) " +
+		"AND created_at < now() - $3::interval"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, "moe", "1 day"}, args)
+}
+
+func TestBetweenToSql(t *testing.T) {
+	b := Between{
+		"created_at": [2]interface{}{"2020-01-01", Expr("NOW()")},
+		"age":        []int{18, 65},
+	}
+	sqlStr, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "age BETWEEN ? AND ? AND created_at BETWEEN ? AND NOW()"
+	assert.Equal(t, expectedSql, sqlStr)
+
+	expectedArgs := []interface{}{18, 65, "2020-01-01"}
+	assert.Equal(t, expectedArgs, args)
+
+	sqlStr, args, err = NotBetween{"n": [2]interface{}{sql.NullInt64{Int64: 1, Valid: true}, 2}}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "n NOT BETWEEN ? AND ?", sqlStr)
+	assert.Equal(t, []interface{}{int64(1), 2}, args)
+}
+
+func TestBetweenErrors(t *testing.T) {
+	_, _, err := Between{"n": [2]interface{}{1, nil}}.ToSql()
+	assert.EqualError(t, err, "cannot use null with between operators")
+
+	_, _, err = Between{"n": [2]interface{}{sql.NullInt64{}, 1}}.ToSql()
+	assert.EqualError(t, err, "cannot use null with between operators")
+
+	_, _, err = Between{"n": []int{1, 2, 3}}.ToSql()
+	assert.EqualError(t, err, "between operators need two bounds, not []int")
+}
+
+func TestRangeToSql(t *testing.T) {
+	sqlStr, args, err := Range("created_at", "2020-01-01", "2021-01-01").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at >= ? AND created_at < ?", sqlStr)
+	assert.Equal(t, []interface{}{"2020-01-01", "2021-01-01"}, args)
+
+	var to *string
+	sqlStr, args, err = Range("created_at", "2020-01-01", to).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at >= ?", sqlStr)
+	assert.Equal(t, []interface{}{"2020-01-01"}, args)
+
+	sqlStr, args, err = Range("n", sql.NullInt64{}, Select("max(n)").From("t")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "n < (SELECT max(n) FROM t)", sqlStr)
+	assert.Nil(t, args)
+
+	sqlStr, _, err = Range("n", nil, nil).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=1)", sqlStr)
+}
+
+func TestNotToSql(t *testing.T) {
+	sqlStr, args, err := Not(Or{Eq{"a": 1}, Like{"b": "x%"}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (a = ? OR b LIKE ?)", sqlStr)
+	assert.Equal(t, []interface{}{1, "x%"}, args)
+
+	sqlStr, _, err = Not(Like{"b": "x%"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (b LIKE ?)", sqlStr)
+
+	sqlStr, _, err = Not(Expr("(a) OR (b)")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT ((a) OR (b))", sqlStr)
+
+	sqlStr, _, err = Not(Expr("(a = ')')")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (a = ')')", sqlStr)
+
+	sqlStr, _, err = Not(Eq{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=0)", sqlStr)
+
+	sqlStr, _, err = Not(Or{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=1)", sqlStr)
+
+	sqlStr, _, err = Select("*").From("t").Where(Not(Exists(Select("1").From("u")))).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE NOT (EXISTS (SELECT 1 FROM u))", sqlStr)
+}
+
+func TestIsDistinctFromToSql(t *testing.T) {
+	b := IsDistinctFrom{"a": 1, "b": nil, "c": Expr("d")}
+	sqlStr, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a IS DISTINCT FROM ? AND b IS DISTINCT FROM NULL AND c IS DISTINCT FROM d", sqlStr)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sqlStr, _, err = IsNotDistinctFrom{"a": 1}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a IS NOT DISTINCT FROM ?", sqlStr)
+
+	sqlStr, _, err = Select("*").From("t").Where(b).Where(IsNotDistinctFrom{"e": 2}).ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE NOT (a <=> ?) AND NOT (b <=> NULL) AND NOT (c <=> d) AND e <=> ?", sqlStr)
+
+	sqlStr, _, err = Select("*").From("t").Where(IsNotDistinctFrom{"e": 2}).ToSqlFor(SQLite)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE e IS NOT DISTINCT FROM ?", sqlStr)
+
+	_, _, err = Select("*").From("t").Where(b).ToSqlFor(Oracle)
+	assert.EqualError(t, err, "Oracle does not support IS DISTINCT FROM")
+
+	_, _, err = IsDistinctFrom{"a": []int{1}}.ToSql()
+	assert.EqualError(t, err, "cannot use array or slice with distinct operators")
+}
+
 func TestExprNilToSql(t *testing.T) {
 	var b Sqlizer
 	b = NotEq{"name": nil}
@@ -462,3 +624,11 @@ func ExampleEq() {
 		"company": 20,
 	})
 }
+
+func TestExprSkipsLiterals(t *testing.T) {
+	sub := Select("id").From("t").Where("a = ?", 1)
+	sql, args, err := Expr("x = '?' AND y ?| array['a'] AND z IN (?)", sub).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "x = '?' AND y ?| array['a'] AND z IN (SELECT id FROM t WHERE a = ?)", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
@@ -0,0 +1,193 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lann/builder"
+)
+
+// Hook is called around the execution of queries, e.g. for logging, metrics
+// or tracing.
+//
+// Hooks are set with StatementBuilderType.Hooks, which runs them for the Exec,
+// Query and QueryRow methods of its builders, or with NewHookRunner, which
+// runs them for every query of a Runner.
+type Hook interface {
+	// BeforeQuery is called before the query is executed. The returned
+	// context is passed to the Runner by the Context methods, e.g.
+	// ExecContext, and to AfterQuery.
+	BeforeQuery(ctx context.Context, info QueryInfo) context.Context
+
+	// AfterQuery is called after the query is executed, with its error and
+	// duration. For QueryRow, errors are only known when the row is scanned,
+	// so err is always nil.
+	AfterQuery(ctx context.Context, info QueryInfo, err error, duration time.Duration)
+}
+
+// QueryInfo describes a query for Hooks.
+type QueryInfo struct {
+	// Kind is the kind of statement, e.g. "SELECT" or "INSERT". For queries
+	// not built by a builder it is the first keyword of the SQL.
+	Kind string
+
+	// Method is the method that executes the query, e.g. "Exec" or
+	// "QueryRowContext".
+	Method string
+
+	SQL  string
+	Args []interface{}
+
+	// Builder is the Sqlizer that built the query, e.g. a SelectBuilder, or
+	// nil for queries run directly on a HookRunner.
+	Builder Sqlizer
+}
+
+// hookedStatement is implemented by the statements of builders, which can
+// have Hooks.
+type hookedStatement interface {
+	statementKind() string
+	statementHooks() []Hook
+}
+
+// statementBuilder is implemented by the builders of statements, whose
+// statement data implement hookedStatement.
+type statementBuilder interface {
+	statement() Sqlizer
+}
+
+// statementOf returns the statement data of s if it is a builder, or else s.
+func statementOf(s Sqlizer) Sqlizer {
+	if b, ok := s.(statementBuilder); ok {
+		return b.statement()
+	}
+	return s
+}
+
+// Hooks adds Hooks for any child builders, which are called when the query is
+// executed with Exec, Query, QueryRow or one of their variants.
+// BeforeQuery is called in the order Hooks were added and AfterQuery in
+// reverse order.
+func (b StatementBuilderType) Hooks(hooks ...Hook) StatementBuilderType {
+	return builder.Extend(b, "Hooks", hooks).(StatementBuilderType)
+}
+
+// newQueryInfo returns the QueryInfo and Hooks of the query built by s, and
+// the runner db to run it on, unwrapping a HookRunner into its Hooks.
+func newQueryInfo(method string, s Sqlizer, query string, args []interface{}, db interface{}) (QueryInfo, []Hook, interface{}) {
+	info := QueryInfo{Method: method, SQL: query, Args: args, Builder: s}
+
+	var hooks []Hook
+	if st, ok := statementOf(s).(hookedStatement); ok {
+		info.Kind = st.statementKind()
+		hooks = st.statementHooks()
+	} else {
+		info.Kind = statementKind(query)
+	}
+
+	if hr, ok := db.(*HookRunner); ok {
+		hooks = append(hooks[:len(hooks):len(hooks)], hr.hooks...)
+		db = hr.runner
+	}
+	return info, hooks, db
+}
+
+// statementKind returns the first keyword of query.
+func statementKind(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// beforeQuery calls the BeforeQuery of hooks and returns the context for the
+// query and a function to call the AfterQuery of hooks with.
+func beforeQuery(ctx context.Context, hooks []Hook, info QueryInfo) (context.Context, func(err error)) {
+	ctxs := make([]context.Context, len(hooks))
+	for i, hook := range hooks {
+		ctxs[i] = ctx
+		ctx = hook.BeforeQuery(ctx, info)
+	}
+
+	start := time.Now()
+	return ctx, func(err error) {
+		duration := time.Since(start)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			hooks[i].AfterQuery(ctx, info, err, duration)
+			ctx = ctxs[i]
+		}
+	}
+}
+
+func hookedExec(db Execer, hooks []Hook, info QueryInfo) (sql.Result, error) {
+	if len(hooks) == 0 {
+		return db.Exec(info.SQL, info.Args...)
+	}
+	_, after := beforeQuery(context.Background(), hooks, info)
+	res, err := db.Exec(info.SQL, info.Args...)
+	after(err)
+	return res, err
+}
+
+func hookedQuery(db Queryer, hooks []Hook, info QueryInfo) (*sql.Rows, error) {
+	if len(hooks) == 0 {
+		return db.Query(info.SQL, info.Args...)
+	}
+	_, after := beforeQuery(context.Background(), hooks, info)
+	rows, err := db.Query(info.SQL, info.Args...)
+	after(err)
+	return rows, err
+}
+
+func hookedQueryRow(db QueryRower, hooks []Hook, info QueryInfo) RowScanner {
+	if len(hooks) == 0 {
+		return db.QueryRow(info.SQL, info.Args...)
+	}
+	_, after := beforeQuery(context.Background(), hooks, info)
+	row := db.QueryRow(info.SQL, info.Args...)
+	after(nil)
+	return row
+}
+
+// HookRunner is a Runner that calls Hooks around every query, see
+// NewHookRunner.
+type HookRunner struct {
+	runner BaseRunner
+	hooks  []Hook
+}
+
+// NewHookRunner returns a Runner that calls hooks around every query it runs
+// with runner, whether it is built by a builder or not.
+//
+// Ex:
+//     db := NewHookRunner(sqlDB, slowQueryLogger)
+//     Select("*").From("users").RunWith(db).Query()
+func NewHookRunner(runner BaseRunner, hooks ...Hook) *HookRunner {
+	return &HookRunner{runner: wrapRunner(runner), hooks: hooks}
+}
+
+// Exec runs the query with the Runner of r.
+func (r *HookRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return hookedExec(r.runner, r.hooks, r.queryInfo("Exec", query, args))
+}
+
+// Query runs the query with the Runner of r.
+func (r *HookRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return hookedQuery(r.runner, r.hooks, r.queryInfo("Query", query, args))
+}
+
+// QueryRow runs the query with the Runner of r, which must be a QueryRower.
+func (r *HookRunner) QueryRow(query string, args ...interface{}) RowScanner {
+	queryRower, ok := r.runner.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return hookedQueryRow(queryRower, r.hooks, r.queryInfo("QueryRow", query, args))
+}
+
+func (r *HookRunner) queryInfo(method, query string, args []interface{}) QueryInfo {
+	return QueryInfo{Kind: statementKind(query), Method: method, SQL: query, Args: args}
+}
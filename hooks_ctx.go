@@ -0,0 +1,66 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+)
+
+func hookedExecContext(ctx context.Context, db ExecerContext, hooks []Hook, info QueryInfo) (sql.Result, error) {
+	if len(hooks) == 0 {
+		return db.ExecContext(ctx, info.SQL, info.Args...)
+	}
+	ctx, after := beforeQuery(ctx, hooks, info)
+	res, err := db.ExecContext(ctx, info.SQL, info.Args...)
+	after(err)
+	return res, err
+}
+
+func hookedQueryContext(ctx context.Context, db QueryerContext, hooks []Hook, info QueryInfo) (*sql.Rows, error) {
+	if len(hooks) == 0 {
+		return db.QueryContext(ctx, info.SQL, info.Args...)
+	}
+	ctx, after := beforeQuery(ctx, hooks, info)
+	rows, err := db.QueryContext(ctx, info.SQL, info.Args...)
+	after(err)
+	return rows, err
+}
+
+func hookedQueryRowContext(ctx context.Context, db QueryRowerContext, hooks []Hook, info QueryInfo) RowScanner {
+	if len(hooks) == 0 {
+		return db.QueryRowContext(ctx, info.SQL, info.Args...)
+	}
+	ctx, after := beforeQuery(ctx, hooks, info)
+	row := db.QueryRowContext(ctx, info.SQL, info.Args...)
+	after(nil)
+	return row
+}
+
+// ExecContext runs the query with the Runner of r, which must be an
+// ExecerContext.
+func (r *HookRunner) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctxRunner, ok := r.runner.(ExecerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return hookedExecContext(ctx, ctxRunner, r.hooks, r.queryInfo("ExecContext", query, args))
+}
+
+// QueryContext runs the query with the Runner of r, which must be a
+// QueryerContext.
+func (r *HookRunner) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctxRunner, ok := r.runner.(QueryerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return hookedQueryContext(ctx, ctxRunner, r.hooks, r.queryInfo("QueryContext", query, args))
+}
+
+// QueryRowContext runs the query with the Runner of r, which must be a
+// QueryRowerContext.
+func (r *HookRunner) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
+	queryRower, ok := r.runner.(QueryRowerContext)
+	if !ok {
+		return &Row{err: NoContextSupport}
+	}
+	return hookedQueryRowContext(ctx, queryRower, r.hooks, r.queryInfo("QueryRowContext", query, args))
+}
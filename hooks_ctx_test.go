@@ -0,0 +1,55 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxRecorder struct {
+	DBStub
+	ctx context.Context
+}
+
+func (r *ctxRecorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.ctx = ctx
+	return r.DBStub.ExecContext(ctx, query, args...)
+}
+
+func TestHooksContext(t *testing.T) {
+	var calls []string
+	h := &recordingHook{name: "h", calls: &calls}
+	r := &recordingHook{name: "r", calls: &calls}
+
+	db := &ctxRecorder{}
+	_, err := StatementBuilder.Hooks(h).
+		Insert("users").Values(1).
+		RunWith(NewHookRunner(db, r)).
+		ExecContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"before h", "before r", "after r true <nil>", "after h true <nil>"}, calls)
+	assert.Equal(t, "INSERT", h.infos[0].Kind)
+	assert.Equal(t, "ExecContext", h.infos[0].Method)
+	assert.IsType(t, InsertBuilder{}, h.infos[0].Builder)
+	assert.IsType(t, InsertBuilder{}, r.infos[0].Builder)
+	assert.Equal(t, "INSERT INTO users VALUES (?)", db.LastExecSql)
+
+	// the context returned by BeforeQuery is passed to the runner
+	assert.Equal(t, true, db.ctx.Value(hookKey("h")))
+	assert.Equal(t, true, db.ctx.Value(hookKey("r")))
+}
+
+func TestHookRunnerNoContextSupport(t *testing.T) {
+	db := NewHookRunner(&DBStub{})
+	_, err := db.QueryContext(ctx, "SELECT 1")
+	assert.NoError(t, err)
+
+	db = NewHookRunner(&struct {
+		Execer
+		Queryer
+	}{&DBStub{}, &DBStub{}})
+	_, err = db.QueryContext(ctx, "SELECT 1")
+	assert.Equal(t, NoContextSupport, err)
+}
@@ -0,0 +1,96 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hookKey string
+
+// recordingHook records its calls, and adds its name to the context.
+type recordingHook struct {
+	name  string
+	calls *[]string
+	infos []QueryInfo
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, info QueryInfo) context.Context {
+	*h.calls = append(*h.calls, "before "+h.name)
+	h.infos = append(h.infos, info)
+	return context.WithValue(ctx, hookKey(h.name), true)
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, info QueryInfo, err error, duration time.Duration) {
+	*h.calls = append(*h.calls, fmt.Sprintf("after %s %v %v", h.name, ctx.Value(hookKey(h.name)), err))
+}
+
+func TestStatementBuilderHooks(t *testing.T) {
+	var calls []string
+	h1 := &recordingHook{name: "h1", calls: &calls}
+	h2 := &recordingHook{name: "h2", calls: &calls}
+
+	db := &DBStub{}
+	sb := StatementBuilder.Hooks(h1).Hooks(h2).RunWith(db)
+
+	_, err := sb.Select("*").From("users").Where("id = ?", 1).Exec()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"before h1", "before h2", "after h2 true <nil>", "after h1 true <nil>"}, calls)
+
+	info := h1.infos[0]
+	assert.Equal(t, "SELECT", info.Kind)
+	assert.Equal(t, "Exec", info.Method)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", info.SQL)
+	assert.Equal(t, []interface{}{1}, info.Args)
+	assert.IsType(t, SelectBuilder{}, info.Builder)
+
+	sb.Replace("users").Values(1).QueryRow()
+	assert.Equal(t, "REPLACE", h1.infos[1].Kind)
+	assert.Equal(t, "QueryRow", h1.infos[1].Method)
+	assert.IsType(t, InsertBuilder{}, h1.infos[1].Builder)
+
+	sb.Delete("x").Prefix("WITH x AS (SELECT 1)").Query()
+	assert.Equal(t, "DELETE", h1.infos[2].Kind)
+	assert.IsType(t, DeleteBuilder{}, h1.infos[2].Builder)
+
+	// builders passed to ExecWith
+	ExecWith(db, StatementBuilder.Hooks(h1).Update("users").Set("a", 1))
+	assert.Equal(t, "UPDATE", h1.infos[3].Kind)
+	assert.IsType(t, UpdateBuilder{}, h1.infos[3].Builder)
+
+	// hooks are not called for queries that fail to build
+	calls = nil
+	_, err = sb.Select().Exec()
+	assert.Error(t, err)
+	assert.Empty(t, calls)
+}
+
+type errExecer struct{ DBStub }
+
+func (e *errExecer) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, errors.New("boom")
+}
+
+func TestHookRunner(t *testing.T) {
+	var calls []string
+	h := &recordingHook{name: "h", calls: &calls}
+	b := &recordingHook{name: "b", calls: &calls}
+
+	db := NewHookRunner(&errExecer{}, h)
+
+	_, err := db.Exec("UPDATE t SET a = ?", 1)
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, []string{"before h", "after h true boom"}, calls)
+	assert.Equal(t, QueryInfo{Kind: "UPDATE", Method: "Exec", SQL: "UPDATE t SET a = ?", Args: []interface{}{1}}, h.infos[0])
+
+	calls = nil
+	StatementBuilder.Hooks(b).Update("t").Set("a", 1).RunWith(db).Exec()
+	assert.Equal(t, []string{"before b", "before h", "after h true boom", "after b true boom"}, calls)
+	assert.Equal(t, "UPDATE", h.infos[1].Kind)
+	assert.IsType(t, UpdateBuilder{}, h.infos[1].Builder)
+}
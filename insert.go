@@ -1,11 +1,10 @@
 package squirrel
 
 import (
-	"bytes"
 	"database/sql"
 	"errors"
 	"fmt"
-	"io"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -14,32 +13,48 @@ import (
 
 type insertData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
+	Hooks             []Hook
+	Comments          []map[string]string
 	Prefixes          []Sqlizer
+	CTEs              []cte
 	StatementKeyword  string
 	Options           []string
 	Into              string
 	Columns           []string
 	Values            [][]interface{}
+	Returning         []string
 	Suffixes          []Sqlizer
-	Select            *SelectBuilder
+	Select            Sqlizer
+
+	ConflictColumns    []string
+	ConflictConstraint string
+	ConflictAction     string
+	ConflictSetClauses []setClause
+	ConflictWhereParts []Sqlizer
+
+	RowAlias               string
+	DuplicateKeySetClauses []setClause
+
+	Err error
 }
 
-func (d *insertData) Exec() (sql.Result, error) {
+func (d *insertData) Exec(b Sqlizer) (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return ExecWith(d.RunWith, d)
+	return ExecWith(d.RunWith, b)
 }
 
-func (d *insertData) Query() (*sql.Rows, error) {
+func (d *insertData) Query(b Sqlizer) (*sql.Rows, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return QueryWith(d.RunWith, d)
+	return QueryWith(d.RunWith, b)
 }
 
-func (d *insertData) QueryRow() RowScanner {
+func (d *insertData) QueryRow(b Sqlizer) RowScanner {
 	if d.RunWith == nil {
 		return &Row{err: RunnerNotSet}
 	}
@@ -47,118 +62,168 @@ func (d *insertData) QueryRow() RowScanner {
 	if !ok {
 		return &Row{err: RunnerNotQueryRunner}
 	}
-	return QueryRowWith(queryRower, d)
+	return QueryRowWith(queryRower, b)
+}
+
+func (d *insertData) statementKind() string {
+	if len(d.StatementKeyword) > 0 {
+		return d.StatementKeyword
+	}
+	return "INSERT"
+}
+
+func (d *insertData) statementHooks() []Hook {
+	return d.Hooks
 }
 
 func (d *insertData) ToSql() (sqlStr string, args []interface{}, err error) {
+	return d.toSqlComment(nil)
+}
+
+func (d *insertData) toSqlComment(tags map[string]string) (string, []interface{}, error) {
+	return statementToSql(d, d.Dialect, d.PlaceholderFormat, append(d.Comments, tags)...)
+}
+
+func (d *insertData) RenderSql(w *SqlWriter) error {
+	if d.Err != nil {
+		return d.Err
+	}
 	if len(d.Into) == 0 {
-		err = errors.New("insert statements must specify a table")
-		return
+		return errors.New("insert statements must specify a table")
 	}
 	if len(d.Values) == 0 && d.Select == nil {
-		err = errors.New("insert statements must have at least one set of values or select clause")
-		return
+		return errors.New("insert statements must have at least one set of values or select clause")
 	}
 
-	sql := &bytes.Buffer{}
+	dialect := w.statementDialect(d.Dialect)
+	output := false
+	if len(d.Returning) > 0 {
+		var err error
+		output, err = useOutput(dialect)
+		if err != nil {
+			return err
+		}
+	}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
-		if err != nil {
-			return
+		if err := w.writeSqlizers(d.Prefixes, " "); err != nil {
+			return err
 		}
 
-		sql.WriteString(" ")
+		w.WriteString(" ")
+	}
+
+	if err := writeWithClause(w, dialect, d.CTEs); err != nil {
+		return err
 	}
 
 	if d.StatementKeyword == "" {
-		sql.WriteString("INSERT ")
+		w.WriteString("INSERT ")
 	} else {
-		sql.WriteString(d.StatementKeyword)
-		sql.WriteString(" ")
+		w.WriteString(d.StatementKeyword)
+		w.WriteString(" ")
 	}
 
 	if len(d.Options) > 0 {
-		sql.WriteString(strings.Join(d.Options, " "))
-		sql.WriteString(" ")
+		w.WriteString(strings.Join(d.Options, " "))
+		w.WriteString(" ")
 	}
 
-	sql.WriteString("INTO ")
-	sql.WriteString(d.Into)
-	sql.WriteString(" ")
+	w.WriteString("INTO ")
+	w.WriteString(d.Into)
+	w.WriteString(" ")
 
 	if len(d.Columns) > 0 {
-		sql.WriteString("(")
-		sql.WriteString(strings.Join(d.Columns, ","))
-		sql.WriteString(") ")
+		w.WriteString("(")
+		w.WriteString(strings.Join(d.Columns, ","))
+		w.WriteString(") ")
+	}
+
+	if len(d.Returning) > 0 && output {
+		w.WriteString(returningClause(d.Returning, true, "INSERTED"))
+		w.WriteString(" ")
 	}
 
+	var err error
 	if d.Select != nil {
-		args, err = d.appendSelectToSQL(sql, args)
+		err = d.writeSelect(w)
 	} else {
-		args, err = d.appendValuesToSQL(sql, args)
+		err = d.writeValues(w)
 	}
 	if err != nil {
-		return
+		return err
+	}
+
+	if len(d.RowAlias) > 0 {
+		if d.Select != nil {
+			return errors.New("row aliases cannot be used with insert select statements")
+		}
+		if err := requireFeature(dialect, FeatureOnDuplicateKey); err != nil {
+			return err
+		}
+		w.WriteString(" AS ")
+		w.WriteString(d.RowAlias)
+	}
+
+	if err := d.writeOnConflict(w, dialect); err != nil {
+		return err
+	}
+
+	if err := d.writeOnDuplicateKey(w, dialect); err != nil {
+		return err
+	}
+
+	if len(d.Returning) > 0 && !output {
+		w.WriteString(" ")
+		w.WriteString(returningClause(d.Returning, false, ""))
 	}
 
 	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+		w.WriteString(" ")
+		if err := w.writeSqlizers(d.Suffixes, " "); err != nil {
+			return err
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
-	return
+	return nil
 }
 
-func (d *insertData) appendValuesToSQL(w io.Writer, args []interface{}) ([]interface{}, error) {
+func (d *insertData) writeValues(w *SqlWriter) error {
 	if len(d.Values) == 0 {
-		return args, errors.New("values for insert statements are not set")
+		return errors.New("values for insert statements are not set")
 	}
 
-	io.WriteString(w, "VALUES ")
+	w.WriteString("VALUES ")
 
-	valuesStrings := make([]string, len(d.Values))
 	for r, row := range d.Values {
-		valueStrings := make([]string, len(row))
+		if r > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("(")
 		for v, val := range row {
+			if v > 0 {
+				w.WriteString(",")
+			}
 			if vs, ok := val.(Sqlizer); ok {
-				vsql, vargs, err := vs.ToSql()
-				if err != nil {
-					return nil, err
+				if err := w.WriteSqlizer(vs); err != nil {
+					return err
 				}
-				valueStrings[v] = vsql
-				args = append(args, vargs...)
 			} else {
-				valueStrings[v] = "?"
-				args = append(args, val)
+				w.WritePlaceholder(val)
 			}
 		}
-		valuesStrings[r] = fmt.Sprintf("(%s)", strings.Join(valueStrings, ","))
+		w.WriteString(")")
 	}
 
-	io.WriteString(w, strings.Join(valuesStrings, ","))
-
-	return args, nil
+	return nil
 }
 
-func (d *insertData) appendSelectToSQL(w io.Writer, args []interface{}) ([]interface{}, error) {
+func (d *insertData) writeSelect(w *SqlWriter) error {
 	if d.Select == nil {
-		return args, errors.New("select clause for insert statements are not set")
-	}
-
-	selectClause, sArgs, err := d.Select.ToSql()
-	if err != nil {
-		return args, err
+		return errors.New("select clause for insert statements are not set")
 	}
 
-	io.WriteString(w, selectClause)
-	args = append(args, sArgs...)
-
-	return args, nil
+	return w.WriteSqlizer(d.Select)
 }
 
 // Builder
@@ -178,6 +243,12 @@ func (b InsertBuilder) PlaceholderFormat(f PlaceholderFormat) InsertBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(InsertBuilder)
 }
 
+// Dialect sets the SQL Dialect (e.g. Postgres or SQLServer) of the query,
+// along with its PlaceholderFormat.
+func (b InsertBuilder) Dialect(d Dialect) InsertBuilder {
+	return setDialect(b, d).(InsertBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -188,19 +259,19 @@ func (b InsertBuilder) RunWith(runner BaseRunner) InsertBuilder {
 // Exec builds and Execs the query with the Runner set by RunWith.
 func (b InsertBuilder) Exec() (sql.Result, error) {
 	data := builder.GetStruct(b).(insertData)
-	return data.Exec()
+	return data.Exec(b)
 }
 
 // Query builds and Querys the query with the Runner set by RunWith.
 func (b InsertBuilder) Query() (*sql.Rows, error) {
 	data := builder.GetStruct(b).(insertData)
-	return data.Query()
+	return data.Query(b)
 }
 
 // QueryRow builds and QueryRows the query with the Runner set by RunWith.
 func (b InsertBuilder) QueryRow() RowScanner {
 	data := builder.GetStruct(b).(insertData)
-	return data.QueryRow()
+	return data.QueryRow(b)
 }
 
 // Scan is a shortcut for QueryRow().Scan.
@@ -216,6 +287,12 @@ func (b InsertBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+// RenderSql renders the query into w, see SqlRenderer.
+func (b InsertBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(insertData)
+	return data.RenderSql(w)
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b InsertBuilder) MustSql() (string, []interface{}) {
@@ -226,6 +303,25 @@ func (b InsertBuilder) MustSql() (string, []interface{}) {
 	return sql, args
 }
 
+// ToSqlFor builds the query into a SQL string and bound args for Dialect d,
+// regardless of the Dialect and PlaceholderFormat set on the builder. The
+// builder itself is not modified.
+func (b InsertBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return b.Dialect(d).ToSql()
+}
+
+// Compile builds the query once into a CompiledQuery, which binds the values
+// of its Param args on each execution with the Runner set by RunWith.
+func (b InsertBuilder) Compile() (*CompiledQuery, error) {
+	data := builder.GetStruct(b).(insertData)
+	return compile(&data, data.RunWith)
+}
+
+func (b InsertBuilder) statement() Sqlizer {
+	data := builder.GetStruct(b).(insertData)
+	return &data
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b InsertBuilder) Prefix(sql string, args ...interface{}) InsertBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -236,6 +332,38 @@ func (b InsertBuilder) PrefixExpr(expr Sqlizer) InsertBuilder {
 	return builder.Append(b, "Prefixes", expr).(InsertBuilder)
 }
 
+// With adds a common table expression to the WITH clause of the query.
+//
+// See SelectBuilder.With for more information.
+func (b InsertBuilder) With(name string, as Sqlizer) InsertBuilder {
+	return builder.Append(b, "CTEs", newCTE(name, as)).(InsertBuilder)
+}
+
+// WithRecursive adds a recursive common table expression with an optional
+// column list to the query; the clause is then rendered as WITH RECURSIVE.
+func (b InsertBuilder) WithRecursive(name string, columns []string, as Sqlizer) InsertBuilder {
+	c := newCTE(name, as)
+	c.columns = columns
+	c.recursive = true
+	return builder.Append(b, "CTEs", c).(InsertBuilder)
+}
+
+// WithMaterialized adds a common table expression with the MATERIALIZED
+// hint (Postgres 12+) to the query.
+func (b InsertBuilder) WithMaterialized(name string, as Sqlizer) InsertBuilder {
+	c := newCTE(name, as)
+	c.materialized = "MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(InsertBuilder)
+}
+
+// WithNotMaterialized adds a common table expression with the
+// NOT MATERIALIZED hint (Postgres 12+) to the query.
+func (b InsertBuilder) WithNotMaterialized(name string, as Sqlizer) InsertBuilder {
+	c := newCTE(name, as)
+	c.materialized = "NOT MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(InsertBuilder)
+}
+
 // Options adds keyword options before the INTO clause of the query.
 func (b InsertBuilder) Options(options ...string) InsertBuilder {
 	return builder.Extend(b, "Options", options).(InsertBuilder)
@@ -256,6 +384,13 @@ func (b InsertBuilder) Values(values ...interface{}) InsertBuilder {
 	return builder.Append(b, "Values", values).(InsertBuilder)
 }
 
+// Returning adds columns to the RETURNING clause of the query, which is
+// rendered before any suffixes. With the SQLServer Dialect it is rendered as
+// e.g. "OUTPUT INSERTED.id" before the values instead.
+func (b InsertBuilder) Returning(columns ...string) InsertBuilder {
+	return builder.Extend(b, "Returning", columns).(InsertBuilder)
+}
+
 // Suffix adds an expression to the end of the query
 func (b InsertBuilder) Suffix(sql string, args ...interface{}) InsertBuilder {
 	return b.SuffixExpr(Expr(sql, args...))
@@ -266,6 +401,11 @@ func (b InsertBuilder) SuffixExpr(expr Sqlizer) InsertBuilder {
 	return builder.Append(b, "Suffixes", expr).(InsertBuilder)
 }
 
+// Comment adds a comment with tags to the query, see SelectBuilder.Comment.
+func (b InsertBuilder) Comment(tags map[string]string) InsertBuilder {
+	return builder.Append(b, "Comments", tags).(InsertBuilder)
+}
+
 // SetMap set columns and values for insert builder from a map of column name and value
 // note that it will reset all previous columns and values was set if any
 func (b InsertBuilder) SetMap(clauses map[string]interface{}) InsertBuilder {
@@ -287,10 +427,164 @@ func (b InsertBuilder) SetMap(clauses map[string]interface{}) InsertBuilder {
 	return b
 }
 
-// Select set Select clause for insert query
+// SetStruct sets columns and values for insert builder from the db tagged
+// fields of struct v, in field order. Fields tagged readonly, and omitempty
+// fields with empty values, are skipped.
+// Like SetMap, it resets all previous columns and values.
+//
+// Ex:
+//     type User struct {
+//         ID      int64  `db:"id,readonly"`
+//         Name    string `db:"name"`
+//         Nick    string `db:"nick,omitempty"`
+//         Version int    `db:"-"`
+//     }
+//     Insert("users").SetStruct(User{Name: "moe"})
+func (b InsertBuilder) SetStruct(v interface{}) InsertBuilder {
+	rv, err := structValue(v)
+	if err != nil {
+		return builder.Set(b, "Err", fmt.Errorf("SetStruct: %v", err)).(InsertBuilder)
+	}
+
+	var cols []string
+	var vals []interface{}
+	for _, f := range structFields(rv.Type()) {
+		if f.readonly {
+			continue
+		}
+		val := f.value(rv)
+		if f.omitEmpty && isEmptyValue(val) {
+			continue
+		}
+		cols = append(cols, f.column)
+		vals = append(vals, val)
+	}
+
+	b = builder.Set(b, "Columns", cols).(InsertBuilder)
+	return builder.Set(b, "Values", [][]interface{}{vals}).(InsertBuilder)
+}
+
+// ValuesStructs sets columns and values for insert builder from a slice of
+// structs, one row per struct, like SetStruct. omitempty is ignored, so that
+// all rows have the same columns.
+// It resets all previous columns and values.
+func (b InsertBuilder) ValuesStructs(slice interface{}) InsertBuilder {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return builder.Set(b, "Err", fmt.Errorf("ValuesStructs: expected a slice of structs, not %T", slice)).(InsertBuilder)
+	}
+
+	var cols []string
+	var fields []structField
+	var t reflect.Type
+	rows := make([][]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		row, err := structValue(rv.Index(i).Interface())
+		if err != nil {
+			return builder.Set(b, "Err", fmt.Errorf("ValuesStructs: %v", err)).(InsertBuilder)
+		}
+		if t == nil {
+			t = row.Type()
+			for _, f := range structFields(t) {
+				if !f.readonly {
+					fields = append(fields, f)
+					cols = append(cols, f.column)
+				}
+			}
+		} else if row.Type() != t {
+			return builder.Set(b, "Err", fmt.Errorf("ValuesStructs: mixed struct types %s and %s", t, row.Type())).(InsertBuilder)
+		}
+
+		vals := make([]interface{}, len(fields))
+		for j, f := range fields {
+			vals[j] = f.value(row)
+		}
+		rows = append(rows, vals)
+	}
+
+	b = builder.Set(b, "Columns", cols).(InsertBuilder)
+	return builder.Set(b, "Values", rows).(InsertBuilder)
+}
+
+// Select set Select clause for insert query, e.g. a SelectBuilder or
+// CompoundSelectBuilder.
 // If Values and Select are used, then Select has higher priority
-func (b InsertBuilder) Select(sb SelectBuilder) InsertBuilder {
-	return builder.Set(b, "Select", &sb).(InsertBuilder)
+func (b InsertBuilder) Select(sb Sqlizer) InsertBuilder {
+	return builder.Set(b, "Select", sb).(InsertBuilder)
+}
+
+// OnConflict sets the conflict target columns of an ON CONFLICT clause
+// (Postgres, SQLite), which is rendered before any suffixes, e.g. RETURNING.
+// The clause also needs an action, see DoNothing and DoUpdateSet.
+//
+// Ex:
+//     Insert("users").Columns("id", "name").Values(1, "moe").
+//         OnConflict("id").
+//         DoUpdateSet("name", Excluded("name"))
+func (b InsertBuilder) OnConflict(columns ...string) InsertBuilder {
+	b = builder.Delete(b, "ConflictConstraint").(InsertBuilder)
+	return builder.Set(b, "ConflictColumns", columns).(InsertBuilder)
+}
+
+// OnConflictOnConstraint sets a named constraint as the conflict target of
+// an ON CONFLICT clause (Postgres).
+func (b InsertBuilder) OnConflictOnConstraint(name string) InsertBuilder {
+	b = builder.Delete(b, "ConflictColumns").(InsertBuilder)
+	return builder.Set(b, "ConflictConstraint", name).(InsertBuilder)
+}
+
+// DoNothing sets DO NOTHING as the action of the ON CONFLICT clause.
+func (b InsertBuilder) DoNothing() InsertBuilder {
+	return builder.Set(b, "ConflictAction", "NOTHING").(InsertBuilder)
+}
+
+// DoUpdateSet adds a SET assignment to the DO UPDATE action of the ON CONFLICT
+// clause. Use Excluded to refer to the value proposed for insertion.
+func (b InsertBuilder) DoUpdateSet(column string, value interface{}) InsertBuilder {
+	b = builder.Set(b, "ConflictAction", "UPDATE").(InsertBuilder)
+	return builder.Append(b, "ConflictSetClauses", setClause{column: column, value: value}).(InsertBuilder)
+}
+
+// DoUpdateSetMap is a convenience method which calls .DoUpdateSet for each
+// key/value pair in clauses.
+func (b InsertBuilder) DoUpdateSetMap(clauses map[string]interface{}) InsertBuilder {
+	b = builder.Set(b, "ConflictAction", "UPDATE").(InsertBuilder)
+	return builder.Extend(b, "ConflictSetClauses", sortedSetClauses(clauses)).(InsertBuilder)
+}
+
+// DoUpdateWhere adds an expression to the WHERE clause of the DO UPDATE
+// action of the ON CONFLICT clause.
+//
+// See SelectBuilder.Where for more information.
+func (b InsertBuilder) DoUpdateWhere(pred interface{}, args ...interface{}) InsertBuilder {
+	return builder.Append(b, "ConflictWhereParts", newWherePart(pred, args...)).(InsertBuilder)
+}
+
+// OnDuplicateKeyUpdate adds an assignment to the ON DUPLICATE KEY UPDATE
+// clause (MySQL), which is rendered before any suffixes. Use Values or a row
+// alias (see RowAlias) to refer to the value proposed for insertion.
+//
+// Ex:
+//     Insert("users").Columns("id", "name").Values(1, "moe").
+//         OnDuplicateKeyUpdate("name", Values("name"))
+func (b InsertBuilder) OnDuplicateKeyUpdate(column string, value interface{}) InsertBuilder {
+	return builder.Append(b, "DuplicateKeySetClauses", setClause{column: column, value: value}).(InsertBuilder)
+}
+
+// OnDuplicateKeyUpdateMap is a convenience method which calls
+// .OnDuplicateKeyUpdate for each key/value pair in clauses.
+func (b InsertBuilder) OnDuplicateKeyUpdateMap(clauses map[string]interface{}) InsertBuilder {
+	return builder.Extend(b, "DuplicateKeySetClauses", sortedSetClauses(clauses)).(InsertBuilder)
+}
+
+// RowAlias sets an alias for the inserted row (MySQL 8.0.19+), which the
+// ON DUPLICATE KEY UPDATE clause can use instead of Values.
+//
+// Ex:
+//     Insert("t").Columns("a", "b").Values(1, 2).RowAlias("new").
+//         OnDuplicateKeyUpdate("b", Expr("new.b"))
+func (b InsertBuilder) RowAlias(alias string) InsertBuilder {
+	return builder.Set(b, "RowAlias", alias).(InsertBuilder)
 }
 
 func (b InsertBuilder) statementKeyword(keyword string) InsertBuilder {
@@ -1,5 +1,3 @@
-// +build go1.8
-
 package squirrel
 
 import (
@@ -9,7 +7,7 @@ import (
 	"github.com/lann/builder"
 )
 
-func (d *insertData) ExecContext(ctx context.Context) (sql.Result, error) {
+func (d *insertData) ExecContext(ctx context.Context, b Sqlizer) (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
@@ -17,10 +15,10 @@ func (d *insertData) ExecContext(ctx context.Context) (sql.Result, error) {
 	if !ok {
 		return nil, NoContextSupport
 	}
-	return ExecContextWith(ctx, ctxRunner, d)
+	return ExecContextWith(ctx, ctxRunner, b)
 }
 
-func (d *insertData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+func (d *insertData) QueryContext(ctx context.Context, b Sqlizer) (*sql.Rows, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
@@ -28,10 +26,10 @@ func (d *insertData) QueryContext(ctx context.Context) (*sql.Rows, error) {
 	if !ok {
 		return nil, NoContextSupport
 	}
-	return QueryContextWith(ctx, ctxRunner, d)
+	return QueryContextWith(ctx, ctxRunner, b)
 }
 
-func (d *insertData) QueryRowContext(ctx context.Context) RowScanner {
+func (d *insertData) QueryRowContext(ctx context.Context, b Sqlizer) RowScanner {
 	if d.RunWith == nil {
 		return &Row{err: RunnerNotSet}
 	}
@@ -42,28 +40,48 @@ func (d *insertData) QueryRowContext(ctx context.Context) RowScanner {
 		}
 		return &Row{err: NoContextSupport}
 	}
-	return QueryRowContextWith(ctx, queryRower, d)
+	return QueryRowContextWith(ctx, queryRower, b)
 }
 
 // ExecContext builds and ExecContexts the query with the Runner set by RunWith.
 func (b InsertBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
 	data := builder.GetStruct(b).(insertData)
-	return data.ExecContext(ctx)
+	return data.ExecContext(ctx, b)
 }
 
 // QueryContext builds and QueryContexts the query with the Runner set by RunWith.
 func (b InsertBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
 	data := builder.GetStruct(b).(insertData)
-	return data.QueryContext(ctx)
+	return data.QueryContext(ctx, b)
 }
 
 // QueryRowContext builds and QueryRowContexts the query with the Runner set by RunWith.
 func (b InsertBuilder) QueryRowContext(ctx context.Context) RowScanner {
 	data := builder.GetStruct(b).(insertData)
-	return data.QueryRowContext(ctx)
+	return data.QueryRowContext(ctx, b)
 }
 
 // ScanContext is a shortcut for QueryRowContext().Scan.
 func (b InsertBuilder) ScanContext(ctx context.Context, dest ...interface{}) error {
 	return b.QueryRowContext(ctx).Scan(dest...)
 }
+
+// ScanStruct scans the first row returned by the query, e.g. with Returning,
+// into the struct dst points to, see SelectBuilder.ScanStruct.
+func (b InsertBuilder) ScanStruct(ctx context.Context, dst interface{}, opts ...ScanOption) error {
+	rows, err := b.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	return scanStruct(rows, dst, opts)
+}
+
+// ScanAll scans all rows returned by the query into the slice dst points to,
+// see SelectBuilder.ScanAll.
+func (b InsertBuilder) ScanAll(ctx context.Context, dst interface{}, opts ...ScanOption) error {
+	rows, err := b.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	return scanAll(rows, dst, opts)
+}
@@ -110,3 +110,15 @@ func TestInsertBuilderReplace(t *testing.T) {
 
 	assert.Equal(t, expectedSQL, sql)
 }
+
+func TestInsertBuilderToSqlFor(t *testing.T) {
+	b := Insert("users").Columns("name").Values("moe").Returning("id")
+
+	sql, _, err := b.ToSqlFor(SQLite)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name) VALUES (?) RETURNING id", sql)
+
+	sql, _, err = b.ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name) OUTPUT INSERTED.id VALUES (@p1)", sql)
+}
@@ -1,6 +1,6 @@
 module github.com/Masterminds/squirrel/integration
 
-go 1.12
+go 1.23
 
 require (
 	github.com/Masterminds/squirrel v1.1.0
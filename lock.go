@@ -0,0 +1,112 @@
+package squirrel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LockStrength is the strength of a row locking clause, see SelectBuilder.For.
+type LockStrength string
+
+const (
+	// LockUpdate locks rows with FOR UPDATE.
+	LockUpdate LockStrength = "UPDATE"
+
+	// LockNoKeyUpdate locks rows with FOR NO KEY UPDATE (Postgres).
+	LockNoKeyUpdate LockStrength = "NO KEY UPDATE"
+
+	// LockShare locks rows with FOR SHARE.
+	LockShare LockStrength = "SHARE"
+
+	// LockKeyShare locks rows with FOR KEY SHARE (Postgres).
+	LockKeyShare LockStrength = "KEY SHARE"
+)
+
+// lockClause is a single "FOR ..." row locking clause of a select.
+type lockClause struct {
+	strength LockStrength
+	of       []string
+	// wait is the optional NOWAIT or SKIP LOCKED wait policy.
+	wait string
+}
+
+func (l lockClause) ToSql() (sqlStr string, args []interface{}, err error) {
+	if len(l.strength) == 0 {
+		err = errors.New("Of, NoWait and SkipLocked require a preceding For")
+		return
+	}
+
+	sql := &bytes.Buffer{}
+	sql.WriteString("FOR ")
+	sql.WriteString(string(l.strength))
+
+	if len(l.of) > 0 {
+		sql.WriteString(" OF ")
+		sql.WriteString(strings.Join(l.of, ", "))
+	}
+
+	if len(l.wait) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(l.wait)
+	}
+
+	sqlStr = sql.String()
+	return
+}
+
+// checkLocks returns an error if dialect d does not support the locking
+// clauses of locks.
+func checkLocks(d Dialect, locks []lockClause) error {
+	if !d.Supports(FeatureLockingClause) {
+		return requireFeature(d, FeatureLockingClause)
+	}
+	for _, l := range locks {
+		switch l.strength {
+		case LockNoKeyUpdate, LockKeyShare:
+			if err := requireFeature(d, FeatureKeyLocks); err != nil {
+				return err
+			}
+		case LockShare:
+			if err := requireFeature(d, FeatureShareLock); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lockTableHints translates locks into the equivalent SQL Server table hints,
+// e.g. "WITH (UPDLOCK, ROWLOCK)".
+func lockTableHints(locks []lockClause) (string, error) {
+	if len(locks) > 1 {
+		return "", errors.New("SQL Server table hints support only a single locking clause")
+	}
+
+	l := locks[0]
+	var hints []string
+	switch l.strength {
+	case LockUpdate, LockNoKeyUpdate:
+		hints = []string{"UPDLOCK", "ROWLOCK"}
+	case LockShare, LockKeyShare:
+		hints = []string{"HOLDLOCK", "ROWLOCK"}
+	case "":
+		return "", errors.New("Of, NoWait and SkipLocked require a preceding For")
+	default:
+		return "", fmt.Errorf("unknown lock strength %q", l.strength)
+	}
+
+	if len(l.of) > 0 {
+		return "", errors.New("SQL Server table hints do not support locking OF specific tables")
+	}
+
+	switch l.wait {
+	case "NOWAIT":
+		hints = append(hints, "NOWAIT")
+	case "SKIP LOCKED":
+		hints = append(hints, "READPAST")
+	}
+
+	return fmt.Sprintf("WITH (%s)", strings.Join(hints, ", ")), nil
+}
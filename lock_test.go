@@ -0,0 +1,125 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderFor(t *testing.T) {
+	b := Select("*").
+		From("jobs").
+		Where("state = ?", "queued").
+		OrderBy("id").
+		Limit(1).
+		Offset(2).
+		For(LockUpdate).
+		SkipLocked().
+		Suffix("-- ?", 3)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM jobs WHERE state = ? ORDER BY id LIMIT 1 OFFSET 2 FOR UPDATE SKIP LOCKED -- ?"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"queued", 3}, args)
+}
+
+func TestSelectBuilderForOf(t *testing.T) {
+	b := Select("*").
+		From("jobs").
+		Join("queues ON queues.id = jobs.queue_id").
+		For(LockNoKeyUpdate).Of("jobs").NoWait().
+		For(LockKeyShare).Of("queues", "workers")
+
+	sql, _, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM jobs JOIN queues ON queues.id = jobs.queue_id " +
+		"FOR NO KEY UPDATE OF jobs NOWAIT FOR KEY SHARE OF queues, workers"
+	assert.Equal(t, expectedSql, sql)
+}
+
+func TestSelectBuilderForImmutable(t *testing.T) {
+	base := Select("*").From("jobs").For(LockShare).Of("jobs")
+	_ = base.Of("other").SkipLocked()
+
+	sql, _, err := base.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs FOR SHARE OF jobs", sql)
+}
+
+func TestSelectBuilderForWithoutStrength(t *testing.T) {
+	_, _, err := Select("*").From("jobs").SkipLocked().ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderForSQLServer(t *testing.T) {
+	b := Select("*").
+		From("jobs").
+		Where("state = ?", "queued").
+		Limit(1).
+		For(LockUpdate).
+		SkipLocked().
+		Dialect(SQLServer)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT TOP (1) * FROM jobs WITH (UPDLOCK, ROWLOCK, READPAST) WHERE state = @p1"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"queued"}, args)
+
+	sql, _, err = Select("*").From("jobs").For(LockShare).NoWait().Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs WITH (HOLDLOCK, ROWLOCK, NOWAIT)", sql)
+}
+
+func TestSelectBuilderForSQLServerErr(t *testing.T) {
+	_, _, err := Select("1").For(LockUpdate).Dialect(SQLServer).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("jobs").For(LockUpdate).Of("jobs").Dialect(SQLServer).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("jobs").For(LockUpdate).For(LockShare).Dialect(SQLServer).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderForSQLite(t *testing.T) {
+	_, _, err := Select("*").From("jobs").For(LockUpdate).Dialect(SQLite).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "SQLite", Feature: FeatureLockingClause}, err)
+
+	// selects nested in a SQLite statement
+	_, _, err = Insert("claimed").Select(Select("id").From("jobs").For(LockUpdate).SkipLocked()).ToSqlFor(SQLite)
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "SQLite", Feature: FeatureLockingClause}, err)
+}
+
+func TestSelectBuilderForKeyLocks(t *testing.T) {
+	b := Select("*").From("jobs").For(LockKeyShare)
+
+	sql, _, err := b.ToSqlFor(Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs FOR KEY SHARE", sql)
+
+	_, _, err = b.ToSqlFor(MySQL)
+	assert.EqualError(t, err, "MySQL does not support FOR NO KEY UPDATE and FOR KEY SHARE")
+
+	sql, _, err = Select("*").From("jobs").For(LockShare).SkipLocked().ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs FOR SHARE SKIP LOCKED", sql)
+}
+
+func TestSelectBuilderForShareOracle(t *testing.T) {
+	sql, _, err := Select("*").From("jobs").For(LockUpdate).SkipLocked().Dialect(Oracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs FOR UPDATE SKIP LOCKED", sql)
+
+	_, _, err = Select("*").From("jobs").For(LockShare).Dialect(Oracle).ToSql()
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "Oracle", Feature: FeatureShareLock}, err)
+}
+
+func TestSelectBuilderForSQLServerSubquery(t *testing.T) {
+	_, _, err := Select("a").FromSelect(Select("a").From("x"), "s").For(LockUpdate).Dialect(SQLServer).ToSql()
+	assert.EqualError(t, err, "locking table hints cannot be used on a FROM subquery")
+}
@@ -0,0 +1,468 @@
+package squirrel
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+type mergeData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           BaseRunner
+	Hooks             []Hook
+	Comments          []map[string]string
+	Prefixes          []Sqlizer
+	CTEs              []cte
+	Into              string
+	Using             Sqlizer
+	OnParts           []Sqlizer
+	WhenClauses       []MergeWhen
+	Suffixes          []Sqlizer
+}
+
+func (d *mergeData) Exec(b Sqlizer) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return ExecWith(d.RunWith, b)
+}
+
+func (d *mergeData) Query(b Sqlizer) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return QueryWith(d.RunWith, b)
+}
+
+func (d *mergeData) QueryRow(b Sqlizer) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := d.RunWith.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return QueryRowWith(queryRower, b)
+}
+
+func (d *mergeData) statementKind() string {
+	return "MERGE"
+}
+
+func (d *mergeData) statementHooks() []Hook {
+	return d.Hooks
+}
+
+func (d *mergeData) ToSql() (sqlStr string, args []interface{}, err error) {
+	return d.toSqlComment(nil)
+}
+
+func (d *mergeData) toSqlComment(tags map[string]string) (string, []interface{}, error) {
+	return statementToSql(d, d.Dialect, d.PlaceholderFormat, append(d.Comments, tags)...)
+}
+
+func (d *mergeData) RenderSql(w *SqlWriter) error {
+	if len(d.Into) == 0 {
+		return errors.New("merge statements must specify a target table")
+	}
+	if d.Using == nil {
+		return errors.New("merge statements must specify a source with Using or UsingSelect")
+	}
+	if len(d.OnParts) == 0 {
+		return errors.New("merge statements must have a join condition")
+	}
+	if len(d.WhenClauses) == 0 {
+		return errors.New("merge statements must have at least one WHEN clause")
+	}
+
+	dialect := w.statementDialect(d.Dialect)
+	if err := requireFeature(dialect, FeatureMerge); err != nil {
+		return err
+	}
+
+	if len(d.Prefixes) > 0 {
+		if err := w.writeSqlizers(d.Prefixes, " "); err != nil {
+			return err
+		}
+
+		w.WriteString(" ")
+	}
+
+	if err := writeWithClause(w, dialect, d.CTEs); err != nil {
+		return err
+	}
+
+	w.WriteString("MERGE INTO ")
+	w.WriteString(d.Into)
+
+	w.WriteString(" USING ")
+	if err := writeTableExpr(w, dialect, d.Using); err != nil {
+		return err
+	}
+
+	// The parentheses are optional except on Oracle.
+	w.WriteString(" ON (")
+	if err := w.writeSqlizers(d.OnParts, " AND "); err != nil {
+		return err
+	}
+	w.WriteString(")")
+
+	for _, when := range d.WhenClauses {
+		w.WriteString(" ")
+		if err := when.writeSql(w, dialect); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Suffixes) > 0 {
+		w.WriteString(" ")
+		if err := w.writeSqlizers(d.Suffixes, " "); err != nil {
+			return err
+		}
+	}
+
+	if dialect.Supports(FeatureMergeTerminator) {
+		w.WriteString(";")
+	}
+
+	return nil
+}
+
+// MergeWhen is a "WHEN [NOT] MATCHED [AND ...] THEN ..." clause of a MERGE
+// statement. Start one with WhenMatched, WhenNotMatched or
+// WhenNotMatchedBySource and finish it with an action, e.g. Set or Insert.
+//
+// MergeWhen values are immutable; every method returns a modified copy.
+type MergeWhen struct {
+	match     string
+	condParts []Sqlizer
+	action    string
+	sets      []setClause
+	columns   []string
+	values    []interface{}
+}
+
+// WhenMatched starts a "WHEN MATCHED" clause, for target rows that have a
+// matching source row.
+func WhenMatched() MergeWhen {
+	return MergeWhen{match: "MATCHED"}
+}
+
+// WhenNotMatched starts a "WHEN NOT MATCHED" clause, for source rows without
+// a matching target row.
+func WhenNotMatched() MergeWhen {
+	return MergeWhen{match: "NOT MATCHED"}
+}
+
+// WhenNotMatchedBySource starts a "WHEN NOT MATCHED BY SOURCE" clause, for
+// target rows without a matching source row (SQL Server, Postgres 17+).
+func WhenNotMatchedBySource() MergeWhen {
+	return MergeWhen{match: "NOT MATCHED BY SOURCE"}
+}
+
+// And adds a condition to the clause. Conditions are ANDed together.
+//
+// See SelectBuilder.Where for the accepted pred types.
+func (w MergeWhen) And(pred interface{}, args ...interface{}) MergeWhen {
+	w.condParts = append(append([]Sqlizer{}, w.condParts...), newWherePart(pred, args...))
+	return w
+}
+
+// Set adds a SET assignment to the "THEN UPDATE" action of the clause.
+func (w MergeWhen) Set(column string, value interface{}) MergeWhen {
+	w.action = "UPDATE"
+	w.sets = append(append([]setClause{}, w.sets...), setClause{column: column, value: value})
+	return w
+}
+
+// SetMap is a convenience method which calls .Set for each key/value pair in
+// clauses.
+func (w MergeWhen) SetMap(clauses map[string]interface{}) MergeWhen {
+	w.action = "UPDATE"
+	w.sets = append(append([]setClause{}, w.sets...), sortedSetClauses(clauses)...)
+	return w
+}
+
+// Delete sets "THEN DELETE" as the action of the clause.
+func (w MergeWhen) Delete() MergeWhen {
+	w.action = "DELETE"
+	return w
+}
+
+// Insert sets "THEN INSERT (columns) VALUES (values)" as the action of the
+// clause. Values may be Sqlizers, e.g. Expr("s.name").
+func (w MergeWhen) Insert(columns []string, values ...interface{}) MergeWhen {
+	w.action = "INSERT"
+	w.columns = columns
+	w.values = values
+	return w
+}
+
+// InsertMap is a convenience method which calls .Insert with the columns and
+// values of clauses, sorted by column.
+func (w MergeWhen) InsertMap(clauses map[string]interface{}) MergeWhen {
+	columns := getSortedKeys(clauses)
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = clauses[column]
+	}
+	return w.Insert(columns, values...)
+}
+
+// DoNothing sets "THEN DO NOTHING" as the action of the clause (Postgres).
+func (w MergeWhen) DoNothing() MergeWhen {
+	w.action = "DO NOTHING"
+	return w
+}
+
+// requireFeatures returns an error if dialect d does not support the syntax
+// of the clause.
+func (w MergeWhen) requireFeatures(d Dialect) error {
+	var features []Feature
+	if w.match == "NOT MATCHED BY SOURCE" {
+		features = append(features, FeatureMergeBySource)
+	}
+	if len(w.condParts) > 0 {
+		features = append(features, FeatureMergeConditions)
+	}
+	switch w.action {
+	case "DELETE":
+		features = append(features, FeatureMergeDelete)
+	case "DO NOTHING":
+		features = append(features, FeatureMergeDoNothing)
+	}
+
+	for _, f := range features {
+		if err := requireFeature(d, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w MergeWhen) writeSql(sql *SqlWriter, d Dialect) error {
+	if err := w.requireFeatures(d); err != nil {
+		return err
+	}
+
+	sql.WriteString("WHEN ")
+	sql.WriteString(w.match)
+
+	if len(w.condParts) > 0 {
+		sql.WriteString(" AND ")
+		if err := sql.writeSqlizers(w.condParts, " AND "); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case w.action == "":
+		return fmt.Errorf("WHEN %s clause has no action", w.match)
+	case w.action == "INSERT" && w.match != "NOT MATCHED":
+		return fmt.Errorf("WHEN %s clauses cannot INSERT", w.match)
+	case (w.action == "UPDATE" || w.action == "DELETE") && w.match == "NOT MATCHED":
+		return fmt.Errorf("WHEN NOT MATCHED clauses cannot %s", w.action)
+	}
+
+	sql.WriteString(" THEN ")
+	switch w.action {
+	case "UPDATE":
+		sql.WriteString("UPDATE SET ")
+		return writeSetClauses(sql, w.sets)
+	case "INSERT":
+		if len(w.columns) > 0 && len(w.columns) != len(w.values) {
+			return fmt.Errorf("merge insert has %d columns but %d values", len(w.columns), len(w.values))
+		}
+		sql.WriteString("INSERT ")
+		if len(w.columns) > 0 {
+			fmt.Fprintf(sql, "(%s) ", strings.Join(w.columns, ","))
+		}
+		sql.WriteString("VALUES (")
+		for i, val := range w.values {
+			if i > 0 {
+				sql.WriteString(",")
+			}
+			if vs, ok := val.(Sqlizer); ok {
+				if err := sql.WriteSqlizer(vs); err != nil {
+					return err
+				}
+			} else {
+				sql.WritePlaceholder(val)
+			}
+		}
+		sql.WriteString(")")
+	default:
+		sql.WriteString(w.action)
+	}
+
+	return nil
+}
+
+// Builder
+
+// MergeBuilder builds SQL MERGE statements.
+type MergeBuilder builder.Builder
+
+func init() {
+	builder.Register(MergeBuilder{}, mergeData{})
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b MergeBuilder) PlaceholderFormat(f PlaceholderFormat) MergeBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(MergeBuilder)
+}
+
+// Dialect sets the SQL Dialect (e.g. Postgres or SQLServer) of the query,
+// along with its PlaceholderFormat.
+func (b MergeBuilder) Dialect(d Dialect) MergeBuilder {
+	return setDialect(b, d).(MergeBuilder)
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
+func (b MergeBuilder) RunWith(runner BaseRunner) MergeBuilder {
+	return setRunWith(b, runner).(MergeBuilder)
+}
+
+// Exec builds and Execs the query with the Runner set by RunWith.
+func (b MergeBuilder) Exec() (sql.Result, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.Exec(b)
+}
+
+// Query builds and Querys the query with the Runner set by RunWith.
+func (b MergeBuilder) Query() (*sql.Rows, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.Query(b)
+}
+
+// QueryRow builds and QueryRows the query with the Runner set by RunWith.
+func (b MergeBuilder) QueryRow() RowScanner {
+	data := builder.GetStruct(b).(mergeData)
+	return data.QueryRow(b)
+}
+
+// Scan is a shortcut for QueryRow().Scan.
+func (b MergeBuilder) Scan(dest ...interface{}) error {
+	return b.QueryRow().Scan(dest...)
+}
+
+// SQL methods
+
+// ToSql builds the query into a SQL string and bound args.
+func (b MergeBuilder) ToSql() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.ToSql()
+}
+
+// RenderSql renders the query into w, see SqlRenderer.
+func (b MergeBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(mergeData)
+	return data.RenderSql(w)
+}
+
+// MustSql builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (b MergeBuilder) MustSql() (string, []interface{}) {
+	sql, args, err := b.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// ToSqlFor builds the query into a SQL string and bound args for Dialect d,
+// regardless of the Dialect and PlaceholderFormat set on the builder. The
+// builder itself is not modified.
+func (b MergeBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return b.Dialect(d).ToSql()
+}
+
+// Compile builds the query once into a CompiledQuery, which binds the values
+// of its Param args on each execution with the Runner set by RunWith.
+func (b MergeBuilder) Compile() (*CompiledQuery, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return compile(&data, data.RunWith)
+}
+
+func (b MergeBuilder) statement() Sqlizer {
+	data := builder.GetStruct(b).(mergeData)
+	return &data
+}
+
+// Prefix adds an expression to the beginning of the query
+func (b MergeBuilder) Prefix(sql string, args ...interface{}) MergeBuilder {
+	return b.PrefixExpr(Expr(sql, args...))
+}
+
+// PrefixExpr adds an expression to the very beginning of the query
+func (b MergeBuilder) PrefixExpr(expr Sqlizer) MergeBuilder {
+	return builder.Append(b, "Prefixes", expr).(MergeBuilder)
+}
+
+// With adds a common table expression to the WITH clause of the query.
+//
+// See SelectBuilder.With for more information.
+func (b MergeBuilder) With(name string, as Sqlizer) MergeBuilder {
+	return builder.Append(b, "CTEs", newCTE(name, as)).(MergeBuilder)
+}
+
+// Into sets the target table of the query.
+func (b MergeBuilder) Into(into string) MergeBuilder {
+	return builder.Set(b, "Into", into).(MergeBuilder)
+}
+
+// Using sets the source table of the query.
+func (b MergeBuilder) Using(source string) MergeBuilder {
+	return builder.Set(b, "Using", newPart(source)).(MergeBuilder)
+}
+
+// UsingSelect sets a subquery (e.g. a SelectBuilder) as the source of the
+// query.
+func (b MergeBuilder) UsingSelect(source Sqlizer, alias string) MergeBuilder {
+	return builder.Set(b, "Using", Alias(source, alias)).(MergeBuilder)
+}
+
+// On adds an expression to the join condition of the target and source.
+// Expressions are ANDed together.
+//
+// See SelectBuilder.Where for the accepted pred types.
+func (b MergeBuilder) On(pred interface{}, args ...interface{}) MergeBuilder {
+	return builder.Append(b, "OnParts", newWherePart(pred, args...)).(MergeBuilder)
+}
+
+// When adds a WHEN clause to the query. Clauses are evaluated in the order
+// they were added.
+//
+// Ex:
+//     Merge("stock s").
+//         Using("deliveries d").
+//         On("s.item_id = d.item_id").
+//         When(WhenMatched().Set("qty", Expr("s.qty + d.qty"))).
+//         When(WhenNotMatched().Insert([]string{"item_id", "qty"}, Expr("d.item_id"), Expr("d.qty")))
+func (b MergeBuilder) When(when MergeWhen) MergeBuilder {
+	return builder.Append(b, "WhenClauses", when).(MergeBuilder)
+}
+
+// Suffix adds an expression to the end of the query
+func (b MergeBuilder) Suffix(sql string, args ...interface{}) MergeBuilder {
+	return b.SuffixExpr(Expr(sql, args...))
+}
+
+// SuffixExpr adds an expression to the end of the query
+func (b MergeBuilder) SuffixExpr(expr Sqlizer) MergeBuilder {
+	return builder.Append(b, "Suffixes", expr).(MergeBuilder)
+}
+
+// Comment adds a comment with tags to the query, see SelectBuilder.Comment.
+func (b MergeBuilder) Comment(tags map[string]string) MergeBuilder {
+	return builder.Append(b, "Comments", tags).(MergeBuilder)
+}
@@ -0,0 +1,161 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBuilderToSql(t *testing.T) {
+	b := Merge("stock s").
+		Prefix("WITH prefix AS ?", 0).
+		Using("deliveries d").
+		On("s.item_id = d.item_id").
+		On(Eq{"d.warehouse": 1}).
+		When(WhenMatched().And("d.qty = ?", 0).Delete()).
+		When(WhenMatched().Set("qty", Expr("s.qty + d.qty")).Set("updated_by", 2)).
+		When(WhenNotMatched().And("d.qty > ?", 3).Insert([]string{"item_id", "qty", "source"}, Expr("d.item_id"), Expr("d.qty"), 4)).
+		When(WhenNotMatched().DoNothing()).
+		Suffix("RETURNING ?", 5)
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH prefix AS $1 MERGE INTO stock s USING deliveries d ON (s.item_id = d.item_id AND d.warehouse = $2) " +
+		"WHEN MATCHED AND d.qty = $3 THEN DELETE " +
+		"WHEN MATCHED THEN UPDATE SET qty = s.qty + d.qty, updated_by = $4 " +
+		"WHEN NOT MATCHED AND d.qty > $5 THEN INSERT (item_id,qty,source) VALUES (d.item_id,d.qty,$6) " +
+		"WHEN NOT MATCHED THEN DO NOTHING " +
+		"RETURNING $7"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{0, 1, 0, 2, 3, 4, 5}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestMergeBuilderUsingSelect(t *testing.T) {
+	src := Select("id", "name").From("staging").Where("batch = ?", 7).PlaceholderFormat(Dollar)
+	b := Merge("users u").
+		With("batch", Select("max(id)").From("batches")).
+		UsingSelect(src, "s").
+		On("u.id = s.id").
+		When(WhenMatched().SetMap(map[string]interface{}{"name": Expr("s.name"), "active": true})).
+		When(WhenNotMatched().InsertMap(map[string]interface{}{"id": Expr("s.id"), "name": Expr("s.name")})).
+		When(WhenNotMatchedBySource().Set("active", false)).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH batch AS (SELECT max(id) FROM batches) " +
+		"MERGE INTO users u USING (SELECT id, name FROM staging WHERE batch = $1) AS s ON (u.id = s.id) " +
+		"WHEN MATCHED THEN UPDATE SET active = $2, name = s.name " +
+		"WHEN NOT MATCHED THEN INSERT (id,name) VALUES (s.id,s.name) " +
+		"WHEN NOT MATCHED BY SOURCE THEN UPDATE SET active = $3"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{7, true, false}, args)
+}
+
+func TestMergeWhenImmutable(t *testing.T) {
+	base := WhenMatched().And("a = 1").Set("x", 1)
+	_ = base.And("b = 2").Set("y", 2)
+
+	sql, _, err := Merge("t").Using("s").On("t.id = s.id").When(base).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED AND a = 1 THEN UPDATE SET x = ?", sql)
+}
+
+func TestMergeBuilderToSqlErr(t *testing.T) {
+	when := WhenMatched().Delete()
+
+	_, _, err := Merge("").Using("s").On("a").When(when).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").On("a").When(when).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s").When(when).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s").On("a").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s").On("a").When(WhenMatched()).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s").On("a").When(WhenMatched().Insert(nil, 1)).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s").On("a").When(WhenNotMatched().Delete()).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s").On("a").When(WhenNotMatched().Insert([]string{"a", "b"}, 1)).ToSql()
+	assert.Error(t, err)
+}
+
+func TestMergeBuilderDialectFeatures(t *testing.T) {
+	b := Merge("t").Using("s").On("t.id = s.id")
+
+	_, _, err := b.When(WhenNotMatched().DoNothing()).ToSqlFor(SQLServer)
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "SQL Server", Feature: FeatureMergeDoNothing}, err)
+
+	_, _, err = b.When(WhenMatched().And("s.x = ?", 1).Set("a", 1)).ToSqlFor(Oracle)
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "Oracle", Feature: FeatureMergeConditions}, err)
+
+	_, _, err = b.When(WhenMatched().Delete()).ToSqlFor(Oracle)
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "Oracle", Feature: FeatureMergeDelete}, err)
+
+	_, _, err = b.When(WhenNotMatchedBySource().Set("active", false)).ToSqlFor(Oracle)
+	assert.Equal(t, &UnsupportedFeatureError{Dialect: "Oracle", Feature: FeatureMergeBySource}, err)
+
+	sql, _, err := b.When(WhenMatched().And("s.x = ?", 1).Delete()).
+		When(WhenNotMatchedBySource().Delete()).
+		ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED AND s.x = @p1 THEN DELETE "+
+		"WHEN NOT MATCHED BY SOURCE THEN DELETE;", sql)
+
+	sql, _, err = b.When(WhenMatched().Set("a", 1)).When(WhenNotMatched().Insert([]string{"id"}, Expr("s.id"))).
+		ToSqlFor(Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED THEN UPDATE SET a = :1 "+
+		"WHEN NOT MATCHED THEN INSERT (id) VALUES (s.id)", sql)
+}
+
+func TestMergeBuilderMustSql(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("TestMergeBuilderMustSql should have panicked!")
+		}
+	}()
+	Merge("").MustSql()
+}
+
+func TestMergeBuilderRunners(t *testing.T) {
+	db := &DBStub{}
+	b := Merge("t").Using("s").On("t.id = s.id").When(WhenMatched().Delete()).RunWith(db)
+
+	expectedSql := "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED THEN DELETE"
+
+	b.Exec()
+	assert.Equal(t, expectedSql, db.LastExecSql)
+
+	b.Query()
+	assert.Equal(t, expectedSql, db.LastQuerySql)
+
+	b.QueryRow()
+	assert.Equal(t, expectedSql, db.LastQueryRowSql)
+
+	err := b.Scan()
+	assert.NoError(t, err)
+}
+
+func TestMergeBuilderNoRunner(t *testing.T) {
+	b := Merge("t").Using("s").On("t.id = s.id").When(WhenMatched().Delete())
+
+	_, err := b.Exec()
+	assert.Equal(t, RunnerNotSet, err)
+
+	err = b.Scan()
+	assert.Equal(t, RunnerNotSet, err)
+}
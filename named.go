@@ -0,0 +1,120 @@
+package squirrel
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Named holds the values of named parameters like :name in a SQL fragment.
+// It is passed as the only arg of Expr, Where and the other methods that take
+// a SQL fragment with args, and a name can be used any number of times.
+//
+// Ex:
+//     Where("created_at > :since AND owner = :owner", Named{"since": t, "owner": id})
+//
+// Named parameters become ? placeholders, or with a Dialect that supports
+// FeatureNamedArgs, e.g. SQLServer and Oracle, they are kept as @name or :name
+// and passed as sql.NamedArg. Sqlizer values are expanded in place like Expr
+// args. Names without a value and values without a name are errors.
+//
+// Colons of :: casts, of :1 placeholders and after identifiers are left alone.
+type Named map[string]interface{}
+
+// namedArgs returns the Named of args with only a Named arg.
+func namedArgs(args []interface{}) (Named, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	names, ok := args[0].(Named)
+	return names, ok
+}
+
+// namedArgPrefix returns the prefix of named placeholders for d, and whether
+// they are passed through as sql.NamedArg at all.
+func namedArgPrefix(d Dialect) (string, bool) {
+	if d == nil || !d.Supports(FeatureNamedArgs) {
+		return "", false
+	}
+	switch d.PlaceholderFormat().(type) {
+	case atpFormat:
+		return "@", true
+	case colonFormat:
+		return ":", true
+	}
+	return "", false
+}
+
+// writeNamed writes query with the named parameters replaced by names.
+func writeNamed(w *SqlWriter, query string, names Named) error {
+	prefix, passThrough := namedArgPrefix(w.Dialect())
+	used := make(map[string]bool, len(names))
+
+	start := 0
+	for i := 0; i < len(query); {
+		if query[i] != ':' {
+			if end := skippedEnd(query, i); end > i {
+				i = end
+			} else {
+				i++
+			}
+			continue
+		}
+
+		if peek(query, i+1) == ':' {
+			i += 2
+			continue
+		}
+
+		end := i + 1
+		for end < len(query) && isNameByte(query[end], end == i+1) {
+			end++
+		}
+		if end == i+1 || i > 0 && isIdentByte(query[i-1]) {
+			i++
+			continue
+		}
+
+		name := query[i+1 : end]
+		value, ok := names[name]
+		if !ok {
+			return fmt.Errorf("missing value for named parameter :%s", name)
+		}
+		used[name] = true
+
+		w.WriteString(query[start:i])
+		if s, ok := value.(Sqlizer); ok {
+			if err := w.WriteSqlizer(s); err != nil {
+				return err
+			}
+		} else if passThrough {
+			w.WriteString(prefix + name)
+			if err := w.AddNamedArg(sql.Named(name, value)); err != nil {
+				return err
+			}
+		} else {
+			w.WritePlaceholder(value)
+		}
+		i = end
+		start = i
+	}
+	w.WriteString(query[start:])
+
+	if len(used) < len(names) {
+		var unused []string
+		for name := range names {
+			if !used[name] {
+				unused = append(unused, name)
+			}
+		}
+		sort.Strings(unused)
+		return fmt.Errorf("unused named parameters: %s", strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+// isNameByte reports whether c can be part of a parameter name, or start it.
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
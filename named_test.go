@@ -0,0 +1,87 @@
+package squirrel
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamedWhere(t *testing.T) {
+	b := Select("*").
+		From("items").
+		Where("created_at > :since AND (owner = :owner OR creator = :owner)", Named{"since": 1, "owner": 2}).
+		Where("kind = ?", "a")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM items WHERE created_at > ? AND (owner = ? OR creator = ?) AND kind = ?", sql)
+	assert.Equal(t, []interface{}{1, 2, 2, "a"}, args)
+
+	sql, args, err = b.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM items WHERE created_at > $1 AND (owner = $2 OR creator = $3) AND kind = $4", sql)
+	assert.Equal(t, []interface{}{1, 2, 2, "a"}, args)
+
+	sql, _, err = b.PlaceholderFormat(AtP).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM items WHERE created_at > @p1 AND (owner = @p2 OR creator = @p3) AND kind = @p4", sql)
+}
+
+func TestNamedPassThrough(t *testing.T) {
+	b := Select("*").
+		From("items").
+		Where("kind = ?", "a").
+		Where("created_at > :since AND (owner = :owner OR creator = :owner)", Named{"since": 1, "owner": 2}).
+		Suffix("OPTION (RECOMPILE) -- :ignored")
+
+	sqlStr, args, err := b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM items WHERE kind = @p1 AND created_at > @since AND (owner = @owner OR creator = @owner) " +
+		"OPTION (RECOMPILE) -- :ignored"
+	assert.Equal(t, expectedSql, sqlStr)
+	assert.Equal(t, []interface{}{"a", sql.Named("since", 1), sql.Named("owner", 2)}, args)
+
+	sqlStr, args, err = b.Dialect(Oracle).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql = "SELECT * FROM items WHERE kind = :1 AND created_at > :since AND (owner = :owner OR creator = :owner) " +
+		"OPTION (RECOMPILE) -- :ignored"
+	assert.Equal(t, expectedSql, sqlStr)
+	assert.Equal(t, []interface{}{"a", sql.Named("since", 1), sql.Named("owner", 2)}, args)
+}
+
+func TestNamedSkipped(t *testing.T) {
+	sql, args, err := Expr("a::text = :a AND b = ':b' AND c[1:n] = \":c\" AND d = :1", Named{"a": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a::text = ? AND b = ':b' AND c[1:n] = \":c\" AND d = :1", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestNamedSqlizerValue(t *testing.T) {
+	sqlStr, args, err := Select("*").
+		From("t").
+		Where("id IN (:ids) AND n > :n", Named{"ids": Select("id").From("u").Where("x = ?", 1), "n": 2}).
+		Dialect(SQLServer).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE x = @p1) AND n > @n", sqlStr)
+	assert.Equal(t, []interface{}{1, sql.Named("n", 2)}, args)
+}
+
+func TestNamedErrors(t *testing.T) {
+	_, _, err := Select("*").From("t").Where("a = :a AND b = :b", Named{"a": 1}).ToSql()
+	assert.EqualError(t, err, "missing value for named parameter :b")
+
+	_, _, err = Select("*").From("t").Where("a = :a", Named{"a": 1, "c": 3, "b": 2}).ToSql()
+	assert.EqualError(t, err, "unused named parameters: b, c")
+
+	_, _, err = Select("*").
+		From("t").
+		Where("a = :a", Named{"a": 1}).
+		Where("b = :a", Named{"a": 2}).
+		Dialect(SQLServer).
+		ToSql()
+	assert.EqualError(t, err, `conflicting values for named arg "a"`)
+}
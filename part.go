@@ -2,7 +2,6 @@ package squirrel
 
 import (
 	"fmt"
-	"io"
 )
 
 type part struct {
@@ -15,49 +14,23 @@ func newPart(pred interface{}, args ...interface{}) Sqlizer {
 }
 
 func (p part) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(p)
+}
+
+func (p part) RenderSql(w *SqlWriter) error {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
 	case Sqlizer:
-		sql, args, err = nestedToSql(pred)
+		return w.WriteSqlizer(pred)
 	case string:
-		sql = pred
-		args = p.args
-	default:
-		err = fmt.Errorf("expected string or Sqlizer, not %T", pred)
-	}
-	return
-}
-
-func nestedToSql(s Sqlizer) (string, []interface{}, error) {
-	if raw, ok := s.(rawSqlizer); ok {
-		return raw.toSqlRaw()
-	} else {
-		return s.ToSql()
-	}
-}
-
-func appendToSql(parts []Sqlizer, w io.Writer, sep string, args []interface{}) ([]interface{}, error) {
-	for i, p := range parts {
-		partSql, partArgs, err := nestedToSql(p)
-		if err != nil {
-			return nil, err
-		} else if len(partSql) == 0 {
-			continue
-		}
-
-		if i > 0 {
-			_, err := io.WriteString(w, sep)
-			if err != nil {
-				return nil, err
-			}
+		if names, ok := namedArgs(p.args); ok {
+			return writeNamed(w, pred, names)
 		}
-
-		_, err = io.WriteString(w, partSql)
-		if err != nil {
-			return nil, err
-		}
-		args = append(args, partArgs...)
+		w.WriteString(pred)
+		w.AddArgs(p.args...)
+	default:
+		return fmt.Errorf("expected string or Sqlizer, not %T", pred)
 	}
-	return args, nil
+	return nil
 }
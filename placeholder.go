@@ -14,10 +14,6 @@ type PlaceholderFormat interface {
 	ReplacePlaceholders(sql string) (string, error)
 }
 
-type placeholderDebugger interface {
-	debugPlaceholder() string
-}
-
 var (
 	// Question is a PlaceholderFormat instance that leaves placeholders as
 	// question marks.
@@ -42,40 +38,24 @@ func (questionFormat) ReplacePlaceholders(sql string) (string, error) {
 	return sql, nil
 }
 
-func (questionFormat) debugPlaceholder() string {
-	return "?"
-}
-
 type dollarFormat struct{}
 
 func (dollarFormat) ReplacePlaceholders(sql string) (string, error) {
 	return replacePositionalPlaceholders(sql, "$")
 }
 
-func (dollarFormat) debugPlaceholder() string {
-	return "$"
-}
-
 type colonFormat struct{}
 
 func (colonFormat) ReplacePlaceholders(sql string) (string, error) {
 	return replacePositionalPlaceholders(sql, ":")
 }
 
-func (colonFormat) debugPlaceholder() string {
-	return ":"
-}
-
 type atpFormat struct{}
 
 func (atpFormat) ReplacePlaceholders(sql string) (string, error) {
 	return replacePositionalPlaceholders(sql, "@p")
 }
 
-func (atpFormat) debugPlaceholder() string {
-	return "@p"
-}
-
 // Placeholders returns a string with count ? placeholders joined with commas.
 func Placeholders(count int) string {
 	if count < 1 {
@@ -88,27 +68,149 @@ func Placeholders(count int) string {
 func replacePositionalPlaceholders(sql, prefix string) (string, error) {
 	buf := &bytes.Buffer{}
 	i := 0
-	for {
-		p := strings.Index(sql, "?")
-		if p == -1 {
-			break
+	err := scanPlaceholders(buf, sql, false, func(buf *bytes.Buffer) error {
+		i++
+		fmt.Fprintf(buf, "%s%d", prefix, i)
+		return nil
+	})
+	return buf.String(), err
+}
+
+// scanPlaceholders copies sql to buf, calling placeholder to write each ?
+// placeholder instead.
+//
+// Question marks in string literals, quoted identifiers, comments and
+// dollar-quoted strings are not placeholders, nor are the Postgres ?| and ?&
+// operators. A bare ? operator has to be escaped as ??, which is written as ?
+// unless keepEscapes is set.
+func scanPlaceholders(buf *bytes.Buffer, sql string, keepEscapes bool, placeholder func(buf *bytes.Buffer) error) error {
+	unescape := func(s string) string {
+		if keepEscapes {
+			return s
 		}
+		return strings.Replace(s, "??", "?", -1)
+	}
 
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
-			buf.WriteString(sql[:p])
-			buf.WriteString("?")
-			if len(sql[p:]) == 1 {
-				break
+	start := 0
+	for i := 0; i < len(sql); {
+		if sql[i] == '?' {
+			buf.WriteString(sql[start:i])
+			switch next := peek(sql, i+1); {
+			case next == '?':
+				buf.WriteString(unescape("??"))
+				i += 2
+			case next == '&' || next == '|' && peek(sql, i+2) != '|':
+				buf.WriteString(sql[i : i+2])
+				i += 2
+			default:
+				if err := placeholder(buf); err != nil {
+					return err
+				}
+				i++
 			}
-			sql = sql[p+2:]
-		} else {
+			start = i
+			continue
+		}
+
+		end := skippedEnd(sql, i)
+		if end == i {
 			i++
-			buf.WriteString(sql[:p])
-			fmt.Fprintf(buf, "%s%d", prefix, i)
-			sql = sql[p+1:]
+			continue
 		}
+
+		// Literals and comments are copied as is, apart from ?? escapes.
+		buf.WriteString(sql[start:i])
+		buf.WriteString(unescape(sql[i:end]))
+		i = end
+		start = i
 	}
 
-	buf.WriteString(sql)
-	return buf.String(), nil
+	buf.WriteString(sql[start:])
+	return nil
+}
+
+// skippedEnd returns the index after the string literal, quoted identifier,
+// comment or dollar-quoted string starting at sql[i], or i if there is none.
+func skippedEnd(sql string, i int) int {
+	switch c := sql[i]; {
+	case c == '\'':
+		backslashes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i < 2 || !isIdentByte(sql[i-2]))
+		return quotedEnd(sql, i, '\'', backslashes)
+	case c == '"' || c == '`':
+		return quotedEnd(sql, i, c, false)
+	case c == '-' && peek(sql, i+1) == '-':
+		end := strings.IndexByte(sql[i:], '\n')
+		if end == -1 {
+			return len(sql)
+		}
+		return end + i + 1
+	case c == '/' && peek(sql, i+1) == '*':
+		end := strings.Index(sql[i+2:], "*/")
+		if end == -1 {
+			return len(sql)
+		}
+		return end + i + 4
+	case c == '$' && (i == 0 || !isIdentByte(sql[i-1])):
+		return dollarQuotedEnd(sql, i)
+	}
+	return i
+}
+
+// peek returns sql[i], or 0 if i is out of range.
+func peek(sql string, i int) byte {
+	if i < len(sql) {
+		return sql[i]
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// quotedEnd returns the index after the quote that closes the string or
+// identifier opened by the quote at sql[start]. Doubled quotes and, if
+// backslashes is set, backslash escapes do not close it.
+func quotedEnd(sql string, start int, quote byte, backslashes bool) int {
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if backslashes {
+				i++
+			}
+		case quote:
+			if peek(sql, i+1) != quote {
+				return i + 1
+			}
+			i++
+		}
+	}
+	return len(sql)
+}
+
+// dollarQuotedEnd returns the index after a dollar-quoted string like
+// $tag$...$tag$ starting at sql[start], or start if there is none.
+func dollarQuotedEnd(sql string, start int) int {
+	i := start + 1
+	for i < len(sql) && isIdentByte(sql[i]) && sql[i] != '$' {
+		if i == start+1 && sql[i] >= '0' && sql[i] <= '9' {
+			// $1 is a positional parameter, not a tag.
+			return start
+		}
+		i++
+	}
+	if peek(sql, i) != '$' {
+		return start
+	}
+
+	tag := sql[start : i+1]
+	end := strings.Index(sql[i+1:], tag)
+	if end == -1 {
+		return len(sql)
+	}
+	return i + 1 + end + len(tag)
 }
@@ -38,19 +38,46 @@ func TestPlaceholders(t *testing.T) {
 func TestEscapeDollar(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := Dollar.ReplacePlaceholders(sql)
-	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['$1'] AND enabled = $2", s)
+	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = $1", s)
 }
 
 func TestEscapeColon(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := Colon.ReplacePlaceholders(sql)
-	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array[':1'] AND enabled = :2", s)
+	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = :1", s)
 }
 
 func TestEscapeAtp(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := AtP.ReplacePlaceholders(sql)
-	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['@p1'] AND enabled = @p2", s)
+	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = @p1", s)
+}
+
+func TestDollarSkipsLiteralsAndComments(t *testing.T) {
+	testCases := []struct {
+		sql      string
+		expected string
+	}{
+		{"a = '?' AND b = ?", "a = '?' AND b = $1"},
+		{"a = 'it''s ?' AND b = ?", "a = 'it''s ?' AND b = $1"},
+		{`a = E'\' ?' AND b = ?`, `a = E'\' ?' AND b = $1`},
+		{`a = 'C:\' AND b = ?`, `a = 'C:\' AND b = $1`},
+		{`"col?" = ? AND ` + "`x?`" + ` = ?`, `"col?" = $1 AND ` + "`x?`" + ` = $2`},
+		{"a = ? -- b = ?\nAND c = ?", "a = $1 -- b = ?\nAND c = $2"},
+		{"a = ? /* b = ? */ AND c = ?", "a = $1 /* b = ? */ AND c = $2"},
+		{"$$ SELECT ? $$ || ?", "$$ SELECT ? $$ || $1"},
+		{"$fn$ SELECT '?' $fn$ || ?", "$fn$ SELECT '?' $fn$ || $1"},
+		{"data ?| array[?] AND data ?& array[?]", "data ?| array[$1] AND data ?& array[$2]"},
+		{"a = ?||b", "a = $1||b"},
+		{"data ?? ? AND x = '??'", "data ? $1 AND x = '?'"},
+		{"a = 'unterminated ?", "a = 'unterminated ?"},
+	}
+
+	for _, tc := range testCases {
+		s, err := Dollar.ReplacePlaceholders(tc.sql)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, s, tc.sql)
+	}
 }
 
 func BenchmarkPlaceholdersArray(b *testing.B) {
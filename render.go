@@ -0,0 +1,206 @@
+package squirrel
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// SqlWriter collects the SQL and args of a statement while its parts render
+// into it.
+//
+// Parts write ? placeholders, which the outermost statement replaces with its
+// PlaceholderFormat exactly once, so placeholders of nested builders are
+// numbered correctly at any depth.
+type SqlWriter struct {
+	buf     bytes.Buffer
+	args    []interface{}
+	named   *[]sql.NamedArg
+	dialect Dialect
+}
+
+// NewSqlWriter returns an empty SqlWriter for a statement in Dialect d, which
+// may be nil.
+func NewSqlWriter(d Dialect) *SqlWriter {
+	return &SqlWriter{dialect: d}
+}
+
+// SqlRenderer is implemented by Sqlizers that render themselves into a
+// SqlWriter, like all the builders of this package.
+//
+// Nested SqlRenderers are rendered with RenderSql instead of ToSql, so they
+// follow the Dialect of the outer statement and leave their placeholders to
+// it. Other Sqlizers must return ? placeholders from ToSql.
+type SqlRenderer interface {
+	Sqlizer
+	RenderSql(w *SqlWriter) error
+}
+
+// Dialect returns the Dialect of the statement being rendered, or nil if it
+// has none.
+func (w *SqlWriter) Dialect() Dialect {
+	return w.dialect
+}
+
+// Write implements io.Writer.
+func (w *SqlWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// WriteString writes SQL text to the statement.
+func (w *SqlWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+// AddArgs appends args for placeholders written to the statement.
+func (w *SqlWriter) AddArgs(args ...interface{}) {
+	if w.args == nil && args != nil {
+		w.args = []interface{}{}
+	}
+	w.args = append(w.args, args...)
+}
+
+// AddNamedArg adds arg for a named placeholder like @name written to the
+// statement. Named args follow all positional args, so they do not shift the
+// positions of numbered placeholders, and each name is only added once.
+func (w *SqlWriter) AddNamedArg(arg sql.NamedArg) error {
+	if w.named == nil {
+		w.named = &[]sql.NamedArg{}
+	}
+	for _, a := range *w.named {
+		if a.Name == arg.Name {
+			if !reflect.DeepEqual(a.Value, arg.Value) {
+				return fmt.Errorf("conflicting values for named arg %q", arg.Name)
+			}
+			return nil
+		}
+	}
+	*w.named = append(*w.named, arg)
+	return nil
+}
+
+// WritePlaceholder writes a ? placeholder for arg.
+func (w *SqlWriter) WritePlaceholder(arg interface{}) {
+	w.buf.WriteString("?")
+	w.args = append(w.args, arg)
+}
+
+// WriteSqlizer renders s into the statement, with RenderSql if s is a
+// SqlRenderer or else with ToSql.
+func (w *SqlWriter) WriteSqlizer(s Sqlizer) error {
+	if r, ok := s.(SqlRenderer); ok {
+		return r.RenderSql(w)
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return err
+	}
+	w.buf.WriteString(sql)
+	w.AddArgs(args...)
+	return nil
+}
+
+// Len returns the length of the SQL written so far.
+func (w *SqlWriter) Len() int {
+	return w.buf.Len()
+}
+
+// String returns the SQL written so far.
+func (w *SqlWriter) String() string {
+	return w.buf.String()
+}
+
+// Args returns the args added so far, followed by the named args.
+func (w *SqlWriter) Args() []interface{} {
+	if w.named == nil || len(*w.named) == 0 {
+		return w.args
+	}
+
+	args := make([]interface{}, 0, len(w.args)+len(*w.named))
+	args = append(args, w.args...)
+	for _, a := range *w.named {
+		args = append(args, a)
+	}
+	return args
+}
+
+// writeSqlizers writes the parts that render to non-empty SQL, separated by
+// sep.
+func (w *SqlWriter) writeSqlizers(parts []Sqlizer, sep string) error {
+	written := false
+	for _, p := range parts {
+		part := w.sub()
+		if err := part.WriteSqlizer(p); err != nil {
+			return err
+		}
+		if part.Len() == 0 {
+			continue
+		}
+
+		if written {
+			w.buf.WriteString(sep)
+		}
+		w.append(part)
+		written = true
+	}
+	return nil
+}
+
+// writeParenthesized writes s in parentheses.
+func (w *SqlWriter) writeParenthesized(s Sqlizer) error {
+	w.buf.WriteString("(")
+	if err := w.WriteSqlizer(s); err != nil {
+		return err
+	}
+	w.buf.WriteString(")")
+	return nil
+}
+
+// sub returns an empty SqlWriter for the same statement, whose contents can
+// be added with append. Named args are shared with w.
+func (w *SqlWriter) sub() *SqlWriter {
+	if w.named == nil {
+		w.named = &[]sql.NamedArg{}
+	}
+	return &SqlWriter{named: w.named, dialect: w.dialect}
+}
+
+func (w *SqlWriter) append(sub *SqlWriter) {
+	w.buf.Write(sub.buf.Bytes())
+	w.AddArgs(sub.args...)
+}
+
+// statementDialect returns the Dialect to render a statement with: the
+// Dialect of the outer statement, if any, or else its own.
+func (w *SqlWriter) statementDialect(own Dialect) Dialect {
+	if w.dialect != nil {
+		return w.dialect
+	}
+	return dialectOrDefault(own)
+}
+
+// renderToSql renders r with ? placeholders.
+func renderToSql(r SqlRenderer) (string, []interface{}, error) {
+	w := NewSqlWriter(nil)
+	if err := r.RenderSql(w); err != nil {
+		return "", nil, err
+	}
+	return w.String(), w.Args(), nil
+}
+
+// statementToSql renders the statement r in Dialect d, replaces its
+// placeholders with f and adds the tags of comments.
+func statementToSql(r SqlRenderer, d Dialect, f PlaceholderFormat, comments ...map[string]string) (string, []interface{}, error) {
+	w := NewSqlWriter(d)
+	if err := r.RenderSql(w); err != nil {
+		return "", nil, err
+	}
+
+	sql, err := f.ReplacePlaceholders(w.String())
+	if err != nil {
+		return "", nil, err
+	}
+	return addComment(dialectOrDefault(d), sql, mergeComments(comments...)), w.Args(), nil
+}
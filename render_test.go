@@ -0,0 +1,93 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertSelectNumbering(t *testing.T) {
+	sql, args, err := Insert("a").
+		Columns("x").
+		Select(Select("x").From("b").Where("y = ?", 1).PlaceholderFormat(Dollar)).
+		Suffix("RETURNING ?", 2).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO a (x) SELECT x FROM b WHERE y = $1 RETURNING $2", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
+
+func TestNestedBuildersNumbering(t *testing.T) {
+	inner := Select("1").From("orders o").Where("o.user_id = u.id AND o.total > ?", 100).PlaceholderFormat(Dollar)
+	status := Case("u.status").When(Expr("?", 0), "'active'").Else("'other'")
+
+	sql, args, err := Select("u.id").
+		Column(Alias(status, "s")).
+		From("users u").
+		Where(And{Eq{"u.team": "a"}, Expr("EXISTS(?)", inner)}).
+		Where(ConcatExpr("u.name = ", Expr("?", "moe"))).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT u.id, (CASE u.status WHEN $1 THEN 'active' ELSE 'other' END) AS s " +
+		"FROM users u WHERE (u.team = $2 AND EXISTS(SELECT 1 FROM orders o WHERE o.user_id = u.id AND o.total > $3)) " +
+		"AND u.name = $4"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{0, "a", 100, "moe"}, args)
+}
+
+func TestNestedBuilderOuterDialect(t *testing.T) {
+	inner := Select("*").From("t").Limit(1).Dialect(Postgres)
+
+	sql, _, err := Select("s.*").FromSelect(inner, "s").Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT s.* FROM (SELECT TOP (1) * FROM t) AS s", sql)
+
+	sql, _, err = inner.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t LIMIT 1", sql)
+}
+
+type quotedColumn string
+
+func (c quotedColumn) ToSql() (string, []interface{}, error) {
+	return renderToSql(c)
+}
+
+func (c quotedColumn) RenderSql(w *SqlWriter) error {
+	d := w.Dialect()
+	if d == nil {
+		d = Postgres
+	}
+	w.WriteString(d.QuoteIdent(string(c)))
+	w.WriteString(" = ")
+	w.WritePlaceholder(string(c))
+	return nil
+}
+
+func TestSqlRenderer(t *testing.T) {
+	sql, args, err := Select("*").
+		From("t").
+		Where("a = ?", 1).
+		Where(quotedColumn("b")).
+		Dialect(MySQL).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = ? AND `b` = ?", sql)
+	assert.Equal(t, []interface{}{1, "b"}, args)
+
+	sql, _, err = Update("t").Set("c", 1).Where(quotedColumn("b")).Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET c = @p1 WHERE [b] = @p2", sql)
+}
+
+func TestSqlWriterWriteSqlizer(t *testing.T) {
+	w := NewSqlWriter(nil)
+	w.WriteString("a = ")
+	assert.NoError(t, w.WriteSqlizer(Expr("? + ?", 1, 2)))
+	assert.Error(t, w.WriteSqlizer(newPart(1)))
+	assert.Equal(t, "a = ? + ?", w.String())
+	assert.Equal(t, []interface{}{1, 2}, w.Args())
+}
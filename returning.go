@@ -0,0 +1,36 @@
+package squirrel
+
+import (
+	"strings"
+)
+
+// useOutput reports whether dialect d returns rows with an OUTPUT clause
+// rather than RETURNING. It fails if d supports neither.
+func useOutput(d Dialect) (bool, error) {
+	if d.Supports(FeatureReturning) {
+		return false, nil
+	}
+	if d.Supports(FeatureOutput) {
+		return true, nil
+	}
+	return false, requireFeature(d, FeatureReturning)
+}
+
+// returningClause renders columns as a RETURNING clause or, if output, as an
+// OUTPUT clause reading from the given pseudo table (INSERTED or DELETED).
+// Columns that are already qualified, e.g. "DELETED.name", are left as is.
+func returningClause(columns []string, output bool, pseudoTable string) string {
+	if !output {
+		return "RETURNING " + strings.Join(columns, ", ")
+	}
+
+	outputs := make([]string, len(columns))
+	for i, column := range columns {
+		if strings.Contains(column, ".") {
+			outputs[i] = column
+		} else {
+			outputs[i] = pseudoTable + "." + column
+		}
+	}
+	return "OUTPUT " + strings.Join(outputs, ", ")
+}
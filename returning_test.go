@@ -0,0 +1,93 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertBuilderReturning(t *testing.T) {
+	b := Insert("users").
+		Columns("name").
+		Values("moe").
+		OnConflict("name").
+		DoNothing().
+		Returning("id", "created_at").
+		Suffix("FETCH FIRST ? ROWS ONLY", 1).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO users (name) VALUES ($1) ON CONFLICT (name) DO NOTHING " +
+		"RETURNING id, created_at FETCH FIRST $2 ROWS ONLY"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"moe", 1}, args)
+}
+
+func TestInsertBuilderReturningSQLServer(t *testing.T) {
+	b := Insert("users").
+		Columns("name").
+		Values("moe").
+		Returning("id", "INSERTED.created_at").
+		Dialect(SQLServer)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO users (name) OUTPUT INSERTED.id, INSERTED.created_at VALUES (@p1)"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"moe"}, args)
+
+	sql, _, err = Insert("a").Returning("*").Select(Select("x").From("b")).Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO a OUTPUT INSERTED.* SELECT x FROM b", sql)
+}
+
+func TestUpdateBuilderReturning(t *testing.T) {
+	b := Update("users").
+		Set("name", "moe").
+		Where("id = ?", 1).
+		Returning("id", "name").
+		Suffix("FETCH FIRST ? ROWS ONLY", 2)
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "UPDATE users SET name = $1 WHERE id = $2 RETURNING id, name FETCH FIRST $3 ROWS ONLY"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"moe", 1, 2}, args)
+
+	sql, args, err = b.Returning("DELETED.name").Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql = "UPDATE users SET name = @p1 OUTPUT INSERTED.id, INSERTED.name, DELETED.name " +
+		"WHERE id = @p2 FETCH FIRST @p3 ROWS ONLY"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"moe", 1, 2}, args)
+}
+
+func TestDeleteBuilderReturning(t *testing.T) {
+	b := Delete("sessions").
+		Where("expires_at < ?", 1).
+		Returning("id")
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM sessions WHERE expires_at < $1 RETURNING id", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, args, err = b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM sessions OUTPUT DELETED.id WHERE expires_at < @p1", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestDeleteBuilderReturningQueryRow(t *testing.T) {
+	db := &DBStub{}
+	var id int
+	err := Delete("jobs").Where("id = ?", 1).Returning("id").RunWith(db).Scan(&id)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM jobs WHERE id = ? RETURNING id", db.LastQueryRowSql)
+	assert.Equal(t, []interface{}{1}, db.LastQueryRowArgs)
+}
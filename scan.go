@@ -0,0 +1,208 @@
+package squirrel
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ScanOption changes how ScanStruct and ScanAll map result columns to struct
+// fields.
+type ScanOption func(*scanOptions)
+
+type scanOptions struct {
+	strict bool
+}
+
+// Strict makes ScanStruct and ScanAll fail on result columns without a db
+// tagged struct field, instead of discarding them.
+func Strict() ScanOption {
+	return func(o *scanOptions) {
+		o.strict = true
+	}
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// rowMapper scans rows into values of a struct type or pointer to one, or of
+// a single column type like int64, *string or sql.NullString.
+type rowMapper struct {
+	ptr     bool
+	scalar  bool
+	indexes [][]int
+}
+
+// isStructRow reports whether the columns of a row are scanned into the
+// fields of t, rather than into a single t.
+func isStructRow(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+func newRowMapper(rows *sql.Rows, t reflect.Type, opts []ScanOption) (*rowMapper, error) {
+	o := scanOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &rowMapper{}
+	if t.Kind() == reflect.Ptr && isStructRow(t.Elem()) {
+		m.ptr = true
+		t = t.Elem()
+	}
+	if !isStructRow(t) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("cannot scan %d columns into %s", len(columns), t)
+		}
+		m.scalar = true
+		return m, nil
+	}
+
+	fields := structFields(t)
+	byColumn := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		byColumn[f.column] = f.index
+	}
+
+	var unmapped []string
+	m.indexes = make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := byColumn[column]
+		if !ok {
+			for _, f := range fields {
+				if strings.EqualFold(f.column, column) {
+					index, ok = f.index, true
+					break
+				}
+			}
+		}
+		if !ok {
+			unmapped = append(unmapped, column)
+		}
+		m.indexes[i] = index
+	}
+	if o.strict && len(unmapped) > 0 {
+		return nil, fmt.Errorf("columns without a db field in %s: %s", t, strings.Join(unmapped, ", "))
+	}
+	return m, nil
+}
+
+// scan scans the current row of rows into v, a settable value of the type of
+// the mapper.
+func (m *rowMapper) scan(rows *sql.Rows, v reflect.Value) error {
+	if m.scalar {
+		return rows.Scan(v.Addr().Interface())
+	}
+	if m.ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	dest := make([]interface{}, len(m.indexes))
+	for i, index := range m.indexes {
+		if index == nil {
+			dest[i] = new(interface{})
+			continue
+		}
+		field, err := fieldByIndexAlloc(v, index)
+		if err != nil {
+			return err
+		}
+		dest[i] = field.Addr().Interface()
+	}
+	return rows.Scan(dest...)
+}
+
+// fieldByIndexAlloc returns the field of v at index, allocating nil embedded
+// structs on the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set nil embedded pointer to unexported %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// scanStruct scans the first row of rows into the struct dst points to and
+// closes rows. dst may also point to a pointer to a struct, or to a single
+// column type. It returns sql.ErrNoRows if there are no rows.
+func scanStruct(rows *sql.Rows, dst interface{}, opts []ScanOption) (err error) {
+	defer closeRows(rows, &err)
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("ScanStruct: expected a pointer, not %T", dst)
+	}
+
+	m, err := newRowMapper(rows, dv.Type().Elem(), opts)
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return m.scan(rows, dv.Elem())
+}
+
+// scanAll scans all rows of rows into the slice dst points to and closes
+// rows, see scanStruct for the element types.
+func scanAll(rows *sql.Rows, dst interface{}, opts []ScanOption) (err error) {
+	defer closeRows(rows, &err)
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ScanAll: expected a pointer to a slice, not %T", dst)
+	}
+
+	sliceType := dv.Type().Elem()
+	elemType := sliceType.Elem()
+
+	m, err := newRowMapper(rows, elemType, opts)
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(sliceType, 0, 0)
+	for rows.Next() {
+		elem := reflect.New(elemType).Elem()
+		if err := m.scan(rows, elem); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dv.Elem().Set(slice)
+	return nil
+}
+
+func closeRows(rows *sql.Rows, err *error) {
+	if cerr := rows.Close(); *err == nil {
+		*err = cerr
+	}
+}
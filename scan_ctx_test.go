@@ -0,0 +1,201 @@
+package squirrel
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scanResult is the result of a query to the scanStub driver.
+type scanResult struct {
+	columns []string
+	values  [][]driver.Value
+	err     error
+	closed  bool
+}
+
+var scanResults = map[string]*scanResult{}
+
+type scanStub struct{}
+
+func (scanStub) Open(name string) (driver.Conn, error) { return scanStub{}, nil }
+
+func (scanStub) Prepare(query string) (driver.Stmt, error) { return scanStmt(query), nil }
+
+func (scanStub) Close() error { return nil }
+
+func (scanStub) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type scanStmt string
+
+func (s scanStmt) Close() error { return nil }
+
+func (s scanStmt) NumInput() int { return -1 }
+
+func (s scanStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s scanStmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, ok := scanResults[string(s)]
+	if !ok {
+		return nil, errors.New("unexpected query " + string(s))
+	}
+	return &scanRows{result: r}, nil
+}
+
+type scanRows struct {
+	result *scanResult
+	i      int
+}
+
+func (r *scanRows) Columns() []string { return r.result.columns }
+
+func (r *scanRows) Close() error {
+	r.result.closed = true
+	return nil
+}
+
+func (r *scanRows) Next(dest []driver.Value) error {
+	if r.i == len(r.result.values) {
+		if r.result.err != nil {
+			return r.result.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.result.values[r.i])
+	r.i++
+	return nil
+}
+
+func init() {
+	sql.Register("squirrel-scan-stub", scanStub{})
+}
+
+type scanBase struct {
+	ID int64 `db:"id,readonly"`
+}
+
+type ScanDetails struct {
+	Phone string `db:"phone"`
+}
+
+type scanUser struct {
+	scanBase
+	*ScanDetails
+	Name string  `db:"name"`
+	Nick *string `db:"nick"`
+	Age  sql.NullInt64
+}
+
+func openScanStub(t *testing.T) *sql.DB {
+	db, err := sql.Open("squirrel-scan-stub", "")
+	assert.NoError(t, err)
+	return db
+}
+
+func TestSelectBuilderScanStruct(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	r := &scanResult{
+		columns: []string{"id", "NAME", "nick", "phone", "extra"},
+		values: [][]driver.Value{
+			{int64(1), "moe", nil, "555", "x"},
+			{int64(2), "larry", "l", nil, "y"},
+		},
+	}
+	scanResults["SELECT * FROM users"] = r
+	b := Select("*").From("users").RunWith(db)
+
+	var u scanUser
+	err := b.ScanStruct(ctx, &u)
+	assert.NoError(t, err)
+	assert.True(t, r.closed)
+	assert.Equal(t, scanUser{scanBase: scanBase{ID: 1}, ScanDetails: &ScanDetails{Phone: "555"}, Name: "moe"}, u)
+
+	err = b.ScanStruct(ctx, &u, Strict())
+	assert.EqualError(t, err, "columns without a db field in squirrel.scanUser: extra")
+
+	var name string
+	err = Select("name").From("users").Where("id = ?", 3).RunWith(db).ScanStruct(ctx, &name)
+	assert.Error(t, err)
+
+	scanResults["SELECT name FROM users WHERE id = ?"] = &scanResult{columns: []string{"name"}}
+	err = Select("name").From("users").Where("id = ?", 3).RunWith(db).ScanStruct(ctx, &name)
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestScanStructShadowing(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	scanResults["SELECT * FROM shadow_users"] = &scanResult{
+		columns: []string{"id", "owner", "name"},
+		values:  [][]driver.Value{{int64(2), "x", "moe"}},
+	}
+
+	var u shadowUser
+	err := Select("*").From("shadow_users").RunWith(db).ScanStruct(ctx, &u)
+	assert.NoError(t, err)
+	assert.Equal(t, shadowUser{ID: 2, Name: "moe"}, u)
+}
+
+func TestSelectBuilderScanAll(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	r := &scanResult{
+		columns: []string{"id", "name", "nick", "Age"},
+		values: [][]driver.Value{
+			{int64(1), "moe", nil, nil},
+			{int64(2), "larry", "l", int64(40)},
+		},
+	}
+	scanResults["SELECT id, name, nick FROM users"] = r
+	b := Select("id", "name", "nick").From("users").RunWith(db)
+
+	var users []*scanUser
+	err := b.ScanAll(ctx, &users)
+	assert.NoError(t, err)
+	assert.True(t, r.closed)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, "moe", users[0].Name)
+		assert.Nil(t, users[0].Nick)
+		assert.Equal(t, int64(2), users[1].ID)
+		assert.Equal(t, "l", *users[1].Nick)
+	}
+
+	// Age has no db tag
+	var strict []scanUser
+	err = b.ScanAll(ctx, &strict, Strict())
+	assert.EqualError(t, err, "columns without a db field in squirrel.scanUser: Age")
+
+	r.err = errors.New("connection lost")
+	r.closed = false
+	err = b.ScanAll(ctx, &users)
+	assert.EqualError(t, err, "connection lost")
+	assert.True(t, r.closed)
+
+	err = b.ScanAll(ctx, users)
+	assert.EqualError(t, err, "ScanAll: expected a pointer to a slice, not []*squirrel.scanUser")
+}
+
+func TestInsertBuilderScanAllReturning(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	scanResults["INSERT INTO users (name) VALUES (?),(?) RETURNING id"] = &scanResult{
+		columns: []string{"id"},
+		values:  [][]driver.Value{{int64(7)}, {int64(8)}},
+	}
+
+	var ids []int64
+	err := Insert("users").Columns("name").Values("moe").Values("larry").Returning("id").RunWith(db).ScanAll(ctx, &ids)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{7, 8}, ids)
+}
@@ -1,7 +1,6 @@
 package squirrel
 
 import (
-	"bytes"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -11,8 +10,12 @@ import (
 
 type selectData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
+	Hooks             []Hook
+	Comments          []map[string]string
 	Prefixes          []Sqlizer
+	CTEs              []cte
 	Options           []string
 	Columns           []Sqlizer
 	From              Sqlizer
@@ -20,27 +23,29 @@ type selectData struct {
 	WhereParts        []Sqlizer
 	GroupBys          []string
 	HavingParts       []Sqlizer
+	Windows           []Sqlizer
 	OrderByParts      []Sqlizer
 	Limit             string
 	Offset            string
+	Locks             []lockClause
 	Suffixes          []Sqlizer
 }
 
-func (d *selectData) Exec() (sql.Result, error) {
+func (d *selectData) Exec(b Sqlizer) (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return ExecWith(d.RunWith, d)
+	return ExecWith(d.RunWith, b)
 }
 
-func (d *selectData) Query() (*sql.Rows, error) {
+func (d *selectData) Query(b Sqlizer) (*sql.Rows, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return QueryWith(d.RunWith, d)
+	return QueryWith(d.RunWith, b)
 }
 
-func (d *selectData) QueryRow() RowScanner {
+func (d *selectData) QueryRow(b Sqlizer) RowScanner {
 	if d.RunWith == nil {
 		return &Row{err: RunnerNotSet}
 	}
@@ -48,116 +53,155 @@ func (d *selectData) QueryRow() RowScanner {
 	if !ok {
 		return &Row{err: RunnerNotQueryRunner}
 	}
-	return QueryRowWith(queryRower, d)
+	return QueryRowWith(queryRower, b)
+}
+
+func (d *selectData) statementKind() string {
+	return "SELECT"
+}
+
+func (d *selectData) statementHooks() []Hook {
+	return d.Hooks
 }
 
 func (d *selectData) ToSql() (sqlStr string, args []interface{}, err error) {
-	sqlStr, args, err = d.toSqlRaw()
-	if err != nil {
-		return
-	}
+	return d.toSqlComment(nil)
+}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
-	return
+func (d *selectData) toSqlComment(tags map[string]string) (string, []interface{}, error) {
+	return statementToSql(d, d.Dialect, d.PlaceholderFormat, append(d.Comments, tags)...)
 }
 
-func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
+func (d *selectData) RenderSql(w *SqlWriter) error {
 	if len(d.Columns) == 0 {
-		err = fmt.Errorf("select statements must have at least one result column")
-		return
+		return fmt.Errorf("select statements must have at least one result column")
 	}
 
-	sql := &bytes.Buffer{}
+	dialect := w.statementDialect(d.Dialect)
+	top, limit, err := limitToSql(dialect, d.Limit, d.Offset, len(d.OrderByParts) > 0, true)
+	if err != nil {
+		return err
+	}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
-		if err != nil {
-			return
+		if err := w.writeSqlizers(d.Prefixes, " "); err != nil {
+			return err
 		}
 
-		sql.WriteString(" ")
+		w.WriteString(" ")
 	}
 
-	sql.WriteString("SELECT ")
+	if err := writeWithClause(w, dialect, d.CTEs); err != nil {
+		return err
+	}
+
+	w.WriteString("SELECT ")
 
 	if len(d.Options) > 0 {
-		sql.WriteString(strings.Join(d.Options, " "))
-		sql.WriteString(" ")
+		w.WriteString(strings.Join(d.Options, " "))
+		w.WriteString(" ")
 	}
 
+	w.WriteString(top)
+
 	if len(d.Columns) > 0 {
-		args, err = appendToSql(d.Columns, sql, ", ", args)
-		if err != nil {
-			return
+		if err := w.writeSqlizers(d.Columns, ", "); err != nil {
+			return err
 		}
 	}
 
 	if d.From != nil {
-		sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		w.WriteString(" FROM ")
+		if err := writeTableExpr(w, dialect, d.From); err != nil {
+			return err
+		}
+	}
+
+	tableHints := len(d.Locks) > 0 && !dialect.Supports(FeatureLockingClause) && dialect.Supports(FeatureLockTableHints)
+	if len(d.Locks) > 0 && !tableHints {
+		if err := checkLocks(dialect, d.Locks); err != nil {
+			return err
+		}
+	}
+	if tableHints {
+		if d.From == nil {
+			return fmt.Errorf("locking table hints require a FROM table")
+		}
+		if _, ok := d.From.(aliasExpr); ok {
+			return fmt.Errorf("locking table hints cannot be used on a FROM subquery")
+		}
+
+		hints, err := lockTableHints(d.Locks)
 		if err != nil {
-			return
+			return err
 		}
+		w.WriteString(" ")
+		w.WriteString(hints)
 	}
 
 	if len(d.Joins) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSql(d.Joins, sql, " ", args)
-		if err != nil {
-			return
+		w.WriteString(" ")
+		if err := w.writeSqlizers(d.Joins, " "); err != nil {
+			return err
 		}
 	}
 
 	if len(d.WhereParts) > 0 {
-		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
-		if err != nil {
-			return
+		w.WriteString(" WHERE ")
+		if err := w.writeSqlizers(d.WhereParts, " AND "); err != nil {
+			return err
 		}
 	}
 
 	if len(d.GroupBys) > 0 {
-		sql.WriteString(" GROUP BY ")
-		sql.WriteString(strings.Join(d.GroupBys, ", "))
+		w.WriteString(" GROUP BY ")
+		w.WriteString(strings.Join(d.GroupBys, ", "))
 	}
 
 	if len(d.HavingParts) > 0 {
-		sql.WriteString(" HAVING ")
-		args, err = appendToSql(d.HavingParts, sql, " AND ", args)
-		if err != nil {
-			return
+		w.WriteString(" HAVING ")
+		if err := w.writeSqlizers(d.HavingParts, " AND "); err != nil {
+			return err
 		}
 	}
 
-	if len(d.OrderByParts) > 0 {
-		sql.WriteString(" ORDER BY ")
-		args, err = appendToSql(d.OrderByParts, sql, ", ", args)
-		if err != nil {
-			return
+	if len(d.Windows) > 0 {
+		w.WriteString(" WINDOW ")
+		if err := w.writeSqlizers(d.Windows, ", "); err != nil {
+			return err
 		}
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(d.OrderByParts) > 0 {
+		w.WriteString(" ORDER BY ")
+		if err := w.writeSqlizers(d.OrderByParts, ", "); err != nil {
+			return err
+		}
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	w.WriteString(limit)
+
+	if len(d.Locks) > 0 && !tableHints {
+		locks := make([]Sqlizer, len(d.Locks))
+		for i, l := range d.Locks {
+			locks[i] = l
+		}
+
+		w.WriteString(" ")
+		if err := w.writeSqlizers(locks, " "); err != nil {
+			return err
+		}
 	}
 
 	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
+		w.WriteString(" ")
 
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+		if err := w.writeSqlizers(d.Suffixes, " "); err != nil {
+			return err
 		}
 	}
 
-	sqlStr = sql.String()
-	return
+	return nil
 }
 
 // Builder
@@ -177,6 +221,12 @@ func (b SelectBuilder) PlaceholderFormat(f PlaceholderFormat) SelectBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(SelectBuilder)
 }
 
+// Dialect sets the SQL Dialect (e.g. Postgres or SQLServer) of the query,
+// along with its PlaceholderFormat.
+func (b SelectBuilder) Dialect(d Dialect) SelectBuilder {
+	return setDialect(b, d).(SelectBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -190,19 +240,19 @@ func (b SelectBuilder) RunWith(runner BaseRunner) SelectBuilder {
 // Exec builds and Execs the query with the Runner set by RunWith.
 func (b SelectBuilder) Exec() (sql.Result, error) {
 	data := builder.GetStruct(b).(selectData)
-	return data.Exec()
+	return data.Exec(b)
 }
 
 // Query builds and Querys the query with the Runner set by RunWith.
 func (b SelectBuilder) Query() (*sql.Rows, error) {
 	data := builder.GetStruct(b).(selectData)
-	return data.Query()
+	return data.Query(b)
 }
 
 // QueryRow builds and QueryRows the query with the Runner set by RunWith.
 func (b SelectBuilder) QueryRow() RowScanner {
 	data := builder.GetStruct(b).(selectData)
-	return data.QueryRow()
+	return data.QueryRow(b)
 }
 
 // Scan is a shortcut for QueryRow().Scan.
@@ -218,9 +268,10 @@ func (b SelectBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
-func (b SelectBuilder) toSqlRaw() (string, []interface{}, error) {
+// RenderSql renders the query into w, see SqlRenderer.
+func (b SelectBuilder) RenderSql(w *SqlWriter) error {
 	data := builder.GetStruct(b).(selectData)
-	return data.toSqlRaw()
+	return data.RenderSql(w)
 }
 
 // MustSql builds the query into a SQL string and bound args.
@@ -233,6 +284,25 @@ func (b SelectBuilder) MustSql() (string, []interface{}) {
 	return sql, args
 }
 
+// ToSqlFor builds the query into a SQL string and bound args for Dialect d,
+// regardless of the Dialect and PlaceholderFormat set on the builder. The
+// builder itself is not modified.
+func (b SelectBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return b.Dialect(d).ToSql()
+}
+
+// Compile builds the query once into a CompiledQuery, which binds the values
+// of its Param args on each execution with the Runner set by RunWith.
+func (b SelectBuilder) Compile() (*CompiledQuery, error) {
+	data := builder.GetStruct(b).(selectData)
+	return compile(&data, data.RunWith)
+}
+
+func (b SelectBuilder) statement() Sqlizer {
+	data := builder.GetStruct(b).(selectData)
+	return &data
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b SelectBuilder) Prefix(sql string, args ...interface{}) SelectBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -243,6 +313,41 @@ func (b SelectBuilder) PrefixExpr(expr Sqlizer) SelectBuilder {
 	return builder.Append(b, "Prefixes", expr).(SelectBuilder)
 }
 
+// With adds a common table expression to the WITH clause of the query.
+// Nested builders are rendered along with the query itself, so their
+// placeholders are numbered correctly under e.g. Dollar.
+//
+// Ex:
+//     With("recent", Select("id").From("orders").Where("created_at > ?", t))
+func (b SelectBuilder) With(name string, as Sqlizer) SelectBuilder {
+	return builder.Append(b, "CTEs", newCTE(name, as)).(SelectBuilder)
+}
+
+// WithRecursive adds a recursive common table expression with an optional
+// column list to the query; the clause is then rendered as WITH RECURSIVE.
+func (b SelectBuilder) WithRecursive(name string, columns []string, as Sqlizer) SelectBuilder {
+	c := newCTE(name, as)
+	c.columns = columns
+	c.recursive = true
+	return builder.Append(b, "CTEs", c).(SelectBuilder)
+}
+
+// WithMaterialized adds a common table expression with the MATERIALIZED
+// hint (Postgres 12+) to the query.
+func (b SelectBuilder) WithMaterialized(name string, as Sqlizer) SelectBuilder {
+	c := newCTE(name, as)
+	c.materialized = "MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(SelectBuilder)
+}
+
+// WithNotMaterialized adds a common table expression with the
+// NOT MATERIALIZED hint (Postgres 12+) to the query.
+func (b SelectBuilder) WithNotMaterialized(name string, as Sqlizer) SelectBuilder {
+	c := newCTE(name, as)
+	c.materialized = "NOT MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(SelectBuilder)
+}
+
 // Distinct adds a DISTINCT clause to the query.
 func (b SelectBuilder) Distinct() SelectBuilder {
 	return b.Options("DISTINCT")
@@ -282,10 +387,9 @@ func (b SelectBuilder) From(from string) SelectBuilder {
 	return builder.Set(b, "From", newPart(from)).(SelectBuilder)
 }
 
-// FromSelect sets a subquery into the FROM clause of the query.
-func (b SelectBuilder) FromSelect(from SelectBuilder, alias string) SelectBuilder {
-	// Prevent misnumbered parameters in nested selects (#183).
-	from = from.PlaceholderFormat(Question)
+// FromSelect sets a subquery (e.g. a SelectBuilder or CompoundSelectBuilder)
+// into the FROM clause of the query.
+func (b SelectBuilder) FromSelect(from Sqlizer, alias string) SelectBuilder {
 	return builder.Set(b, "From", Alias(from, alias)).(SelectBuilder)
 }
 
@@ -358,6 +462,18 @@ func (b SelectBuilder) Having(pred interface{}, rest ...interface{}) SelectBuild
 	return builder.Append(b, "HavingParts", newWherePart(pred, rest...)).(SelectBuilder)
 }
 
+// Window adds a named window definition to the WINDOW clause of the query.
+// Window functions can refer to it with Over(...).Extends(name).
+//
+// Ex:
+//     Select("id").
+//         Column(Over("rank()").Extends("w")).
+//         From("emp").
+//         Window("w", Window().PartitionBy("dept").OrderBy("salary DESC"))
+func (b SelectBuilder) Window(name string, spec WindowBuilder) SelectBuilder {
+	return builder.Append(b, "Windows", namedWindow{name: name, spec: spec}).(SelectBuilder)
+}
+
 // OrderByClause adds ORDER BY clause to the query.
 func (b SelectBuilder) OrderByClause(pred interface{}, args ...interface{}) SelectBuilder {
 	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(SelectBuilder)
@@ -372,7 +488,8 @@ func (b SelectBuilder) OrderBy(orderBys ...string) SelectBuilder {
 	return b
 }
 
-// Limit sets a LIMIT clause on the query.
+// Limit sets a LIMIT clause on the query. Depending on the Dialect it is
+// rendered as "TOP (n)" or "FETCH NEXT n ROWS ONLY" instead.
 func (b SelectBuilder) Limit(limit uint64) SelectBuilder {
 	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(SelectBuilder)
 }
@@ -392,6 +509,56 @@ func (b SelectBuilder) RemoveOffset() SelectBuilder {
 	return builder.Delete(b, "Offset").(SelectBuilder)
 }
 
+// For adds a row locking clause, e.g. FOR UPDATE, to the query. It is
+// rendered after LIMIT and OFFSET, before any suffixes.
+//
+// Of, NoWait and SkipLocked apply to the most recently added locking clause:
+//     Select("*").From("jobs").Limit(1).For(LockUpdate).SkipLocked()
+//
+// With the SQLServer Dialect, the clause is rendered as table hints on the
+// FROM table instead, e.g. "FROM jobs WITH (UPDLOCK, ROWLOCK)".
+func (b SelectBuilder) For(strength LockStrength) SelectBuilder {
+	return builder.Append(b, "Locks", lockClause{strength: strength}).(SelectBuilder)
+}
+
+// Of restricts the last locking clause to the given tables.
+func (b SelectBuilder) Of(tables ...string) SelectBuilder {
+	return b.updateLastLock(func(l *lockClause) {
+		l.of = append(append([]string{}, l.of...), tables...)
+	})
+}
+
+// NoWait makes the last locking clause fail instead of waiting for locked rows.
+func (b SelectBuilder) NoWait() SelectBuilder {
+	return b.updateLastLock(func(l *lockClause) {
+		l.wait = "NOWAIT"
+	})
+}
+
+// SkipLocked makes the last locking clause skip rows that are already locked.
+func (b SelectBuilder) SkipLocked() SelectBuilder {
+	return b.updateLastLock(func(l *lockClause) {
+		l.wait = "SKIP LOCKED"
+	})
+}
+
+// updateLastLock replaces the last locking clause with a copy modified by
+// update. Without a locking clause, update is applied to an empty one, which
+// fails when the query is built.
+func (b SelectBuilder) updateLastLock(update func(*lockClause)) SelectBuilder {
+	var locks []lockClause
+	if v, ok := builder.Get(b, "Locks"); ok {
+		locks = append(locks, v.([]lockClause)...)
+	}
+	if len(locks) == 0 {
+		locks = append(locks, lockClause{})
+	}
+	update(&locks[len(locks)-1])
+
+	b = builder.Delete(b, "Locks").(SelectBuilder)
+	return builder.Extend(b, "Locks", locks).(SelectBuilder)
+}
+
 // Suffix adds an expression to the end of the query
 func (b SelectBuilder) Suffix(sql string, args ...interface{}) SelectBuilder {
 	return b.SuffixExpr(Expr(sql, args...))
@@ -401,3 +568,18 @@ func (b SelectBuilder) Suffix(sql string, args ...interface{}) SelectBuilder {
 func (b SelectBuilder) SuffixExpr(expr Sqlizer) SelectBuilder {
 	return builder.Append(b, "Suffixes", expr).(SelectBuilder)
 }
+
+// Comment adds a comment with tags, e.g. the route or action that runs the
+// query, to the query in the format of sqlcommenter:
+//     /*action='list',route='%2Fusers'*/
+// Tags are added to those set earlier, e.g. by StatementBuilder.Comment, and
+// by WithComment on the context of the query.
+//
+// The comment is only added to the outermost statement, at the end unless
+// its Dialect supports FeatureLeadingComment.
+//
+// Ex:
+//     Select("*").From("users").Comment(map[string]string{"action": "list"})
+func (b SelectBuilder) Comment(tags map[string]string) SelectBuilder {
+	return builder.Append(b, "Comments", tags).(SelectBuilder)
+}
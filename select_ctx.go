@@ -1,5 +1,3 @@
-// +build go1.8
-
 package squirrel
 
 import (
@@ -9,7 +7,7 @@ import (
 	"github.com/lann/builder"
 )
 
-func (d *selectData) ExecContext(ctx context.Context) (sql.Result, error) {
+func (d *selectData) ExecContext(ctx context.Context, b Sqlizer) (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
@@ -17,10 +15,10 @@ func (d *selectData) ExecContext(ctx context.Context) (sql.Result, error) {
 	if !ok {
 		return nil, NoContextSupport
 	}
-	return ExecContextWith(ctx, ctxRunner, d)
+	return ExecContextWith(ctx, ctxRunner, b)
 }
 
-func (d *selectData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+func (d *selectData) QueryContext(ctx context.Context, b Sqlizer) (*sql.Rows, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
@@ -28,10 +26,10 @@ func (d *selectData) QueryContext(ctx context.Context) (*sql.Rows, error) {
 	if !ok {
 		return nil, NoContextSupport
 	}
-	return QueryContextWith(ctx, ctxRunner, d)
+	return QueryContextWith(ctx, ctxRunner, b)
 }
 
-func (d *selectData) QueryRowContext(ctx context.Context) RowScanner {
+func (d *selectData) QueryRowContext(ctx context.Context, b Sqlizer) RowScanner {
 	if d.RunWith == nil {
 		return &Row{err: RunnerNotSet}
 	}
@@ -42,28 +40,51 @@ func (d *selectData) QueryRowContext(ctx context.Context) RowScanner {
 		}
 		return &Row{err: NoContextSupport}
 	}
-	return QueryRowContextWith(ctx, queryRower, d)
+	return QueryRowContextWith(ctx, queryRower, b)
 }
 
 // ExecContext builds and ExecContexts the query with the Runner set by RunWith.
 func (b SelectBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
 	data := builder.GetStruct(b).(selectData)
-	return data.ExecContext(ctx)
+	return data.ExecContext(ctx, b)
 }
 
 // QueryContext builds and QueryContexts the query with the Runner set by RunWith.
 func (b SelectBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
 	data := builder.GetStruct(b).(selectData)
-	return data.QueryContext(ctx)
+	return data.QueryContext(ctx, b)
 }
 
 // QueryRowContext builds and QueryRowContexts the query with the Runner set by RunWith.
 func (b SelectBuilder) QueryRowContext(ctx context.Context) RowScanner {
 	data := builder.GetStruct(b).(selectData)
-	return data.QueryRowContext(ctx)
+	return data.QueryRowContext(ctx, b)
 }
 
 // ScanContext is a shortcut for QueryRowContext().Scan.
 func (b SelectBuilder) ScanContext(ctx context.Context, dest ...interface{}) error {
 	return b.QueryRowContext(ctx).Scan(dest...)
 }
+
+// ScanStruct runs the query with QueryContext and scans the first row into
+// the struct dst points to, mapping columns to fields by their db tags, see
+// InsertBuilder.SetStruct. Nil embedded structs are allocated, and pointer
+// fields are set to nil for NULL values.
+// It returns sql.ErrNoRows if there are no rows.
+func (b SelectBuilder) ScanStruct(ctx context.Context, dst interface{}, opts ...ScanOption) error {
+	rows, err := b.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	return scanStruct(rows, dst, opts)
+}
+
+// ScanAll runs the query with QueryContext and scans all rows into the
+// slice dst points to, e.g. a *[]User or *[]*User, like ScanStruct.
+func (b SelectBuilder) ScanAll(ctx context.Context, dst interface{}, opts ...ScanOption) error {
+	rows, err := b.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	return scanAll(rows, dst, opts)
+}
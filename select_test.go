@@ -461,3 +461,21 @@ func TestRemoveColumns(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "SELECT name FROM users", sql)
 }
+
+func TestSelectBuilderToSqlFor(t *testing.T) {
+	b := Select("*").From("users").Where("id = ?", 1).Limit(1)
+
+	sql, args, err := b.ToSqlFor(Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = $1 LIMIT 1", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _, err = b.ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT TOP (1) * FROM users WHERE id = @p1", sql)
+
+	// The builder itself is unchanged.
+	sql, _, err = b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? LIMIT 1", sql)
+}
@@ -6,7 +6,9 @@ package squirrel
 import (
 	"bytes"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/lann/builder"
@@ -20,12 +22,6 @@ type Sqlizer interface {
 	ToSql() (string, []interface{}, error)
 }
 
-// rawSqlizer is expected to do what Sqlizer does, but without finalizing placeholders.
-// This is useful for nested queries.
-type rawSqlizer interface {
-	toSqlRaw() (string, []interface{}, error)
-}
-
 // Execer is the interface that wraps the Exec method.
 //
 // Exec executes the given query as implemented by database/sql.Exec.
@@ -83,13 +79,23 @@ func (r *stdsqlRunner) QueryRow(query string, args ...interface{}) RowScanner {
 }
 
 func setRunWith(b interface{}, runner BaseRunner) interface{} {
+	return builder.Set(b, "RunWith", wrapRunner(runner))
+}
+
+// wrapRunner wraps runners with the standard SQL interface, see WrapStdSql.
+func wrapRunner(runner BaseRunner) BaseRunner {
 	switch r := runner.(type) {
 	case StdSqlCtx:
-		runner = WrapStdSqlCtx(r)
+		return WrapStdSqlCtx(r)
 	case StdSql:
-		runner = WrapStdSql(r)
+		return WrapStdSql(r)
 	}
-	return builder.Set(b, "RunWith", runner)
+	return runner
+}
+
+func setDialect(b interface{}, d Dialect) interface{} {
+	b = builder.Set(b, "Dialect", d)
+	return builder.Set(b, "PlaceholderFormat", d.PlaceholderFormat())
 }
 
 // RunnerNotSet is returned by methods that need a Runner if it isn't set.
@@ -104,7 +110,8 @@ func ExecWith(db Execer, s Sqlizer) (res sql.Result, err error) {
 	if err != nil {
 		return
 	}
-	return db.Exec(query, args...)
+	info, hooks, runner := newQueryInfo("Exec", s, query, args, db)
+	return hookedExec(runner.(Execer), hooks, info)
 }
 
 // QueryWith Querys the SQL returned by s with db.
@@ -113,13 +120,22 @@ func QueryWith(db Queryer, s Sqlizer) (rows *sql.Rows, err error) {
 	if err != nil {
 		return
 	}
-	return db.Query(query, args...)
+	info, hooks, runner := newQueryInfo("Query", s, query, args, db)
+	return hookedQuery(runner.(Queryer), hooks, info)
 }
 
 // QueryRowWith QueryRows the SQL returned by s with db.
 func QueryRowWith(db QueryRower, s Sqlizer) RowScanner {
 	query, args, err := s.ToSql()
-	return &Row{RowScanner: db.QueryRow(query, args...), err: err}
+	if err != nil {
+		return &Row{err: err}
+	}
+	info, hooks, runner := newQueryInfo("QueryRow", s, query, args, db)
+	queryRower, ok := runner.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return &Row{RowScanner: hookedQueryRow(queryRower, hooks, info)}
 }
 
 // DebugSqlizer calls ToSql on s and shows the approximate SQL to be executed
@@ -137,47 +153,108 @@ func DebugSqlizer(s Sqlizer) string {
 		return fmt.Sprintf("[ToSql error: %s]", err)
 	}
 
-	var placeholder string
-	downCast, ok := s.(placeholderDebugger)
-	if !ok {
-		placeholder = "?"
-	} else {
-		placeholder = downCast.debugPlaceholder()
+	debug, err := inlineArgs(sql, args)
+	if err != nil {
+		return fmt.Sprintf("[DebugSqlizer error: %s in %#v for %d args]", err, sql, len(args))
+	}
+	return debug
+}
+
+// inlineArgs replaces the placeholders of sql with args: ? placeholders,
+// numbered ones like $1, :1 and @p1, and @name or :name for sql.NamedArg
+// args. Placeholders in literals and comments are skipped, see
+// scanPlaceholders.
+func inlineArgs(sqlStr string, args []interface{}) (string, error) {
+	used := make([]bool, len(args))
+	named := map[string]int{}
+	for i, arg := range args {
+		if na, ok := arg.(sql.NamedArg); ok {
+			named[na.Name] = i
+		}
 	}
-	// TODO: dedupe this with placeholder.go
+
 	buf := &bytes.Buffer{}
-	i := 0
-	for {
-		p := strings.Index(sql, placeholder)
-		if p == -1 {
-			break
+	writeArg := func(i int) error {
+		if i < 0 || i >= len(args) {
+			return errors.New("too many placeholders")
+		}
+		used[i] = true
+		arg := args[i]
+		if na, ok := arg.(sql.NamedArg); ok {
+			arg = na.Value
+		}
+		fmt.Fprintf(buf, "'%v'", arg)
+		return nil
+	}
+
+	next := 0
+	for i := 0; i < len(sqlStr); {
+		c := sqlStr[i]
+		afterIdent := i > 0 && (isIdentByte(sqlStr[i-1]) || sqlStr[i-1] == ':')
+
+		if c == '?' {
+			switch n := peek(sqlStr, i+1); {
+			case n == '?':
+				buf.WriteString("?")
+				i += 2
+			case n == '&' || n == '|' && peek(sqlStr, i+2) != '|':
+				buf.WriteString(sqlStr[i : i+2])
+				i += 2
+			default:
+				if err := writeArg(next); err != nil {
+					return "", err
+				}
+				next++
+				i++
+			}
+			continue
 		}
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
-			buf.WriteString(sql[:p])
-			buf.WriteString("?")
-			if len(sql[p:]) == 1 {
-				break
+
+		if (c == '$' || c == ':' || c == '@') && !afterIdent {
+			start := i + 1
+			if c == '@' && peek(sqlStr, start) == 'p' && isDigit(peek(sqlStr, start+1)) {
+				start++
 			}
-			sql = sql[p+2:]
-		} else {
-			if i+1 > len(args) {
-				return fmt.Sprintf(
-					"[DebugSqlizer error: too many placeholders in %#v for %d args]",
-					sql, len(args))
+			end := start
+			for end < len(sqlStr) && isDigit(sqlStr[end]) {
+				end++
+			}
+			if end > start && (c != '@' || start > i+1) {
+				n, _ := strconv.Atoi(sqlStr[start:end])
+				if err := writeArg(n - 1); err != nil {
+					return "", err
+				}
+				i = end
+				continue
+			}
+
+			end = i + 1
+			for end < len(sqlStr) && isNameByte(sqlStr[end], end == i+1) {
+				end++
+			}
+			if pos, ok := named[sqlStr[i+1:end]]; ok && c != '$' {
+				if err := writeArg(pos); err != nil {
+					return "", err
+				}
+				i = end
+				continue
 			}
-			buf.WriteString(sql[:p])
-			fmt.Fprintf(buf, "'%v'", args[i])
-			// advance our sql string "cursor" beyond the arg we placed
-			sql = sql[p+1:]
-			i++
 		}
+
+		// Literals and comments are copied as is, apart from ?? escapes.
+		if end := skippedEnd(sqlStr, i); end > i {
+			buf.WriteString(strings.Replace(sqlStr[i:end], "??", "?", -1))
+			i = end
+			continue
+		}
+		buf.WriteByte(c)
+		i++
 	}
-	if i < len(args) {
-		return fmt.Sprintf(
-			"[DebugSqlizer error: not enough placeholders in %#v for %d args]",
-			sql, len(args))
+
+	for _, u := range used {
+		if !u {
+			return "", errors.New("not enough placeholders")
+		}
 	}
-	// "append" any remaning sql that won't need interpolating
-	buf.WriteString(sql)
-	return buf.String()
+	return buf.String(), nil
 }
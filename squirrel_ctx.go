@@ -1,5 +1,3 @@
-// +build go1.8
-
 package squirrel
 
 import (
@@ -70,24 +68,42 @@ func (r *stdsqlCtxRunner) QueryRowContext(ctx context.Context, query string, arg
 
 // ExecContextWith ExecContexts the SQL returned by s with db.
 func ExecContextWith(ctx context.Context, db ExecerContext, s Sqlizer) (res sql.Result, err error) {
-	query, args, err := s.ToSql()
+	query, args, err := toSqlContext(ctx, s)
 	if err != nil {
 		return
 	}
-	return db.ExecContext(ctx, query, args...)
+	info, hooks, runner := newQueryInfo("ExecContext", s, query, args, db)
+	ctxRunner, ok := runner.(ExecerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return hookedExecContext(ctx, ctxRunner, hooks, info)
 }
 
 // QueryContextWith QueryContexts the SQL returned by s with db.
 func QueryContextWith(ctx context.Context, db QueryerContext, s Sqlizer) (rows *sql.Rows, err error) {
-	query, args, err := s.ToSql()
+	query, args, err := toSqlContext(ctx, s)
 	if err != nil {
 		return
 	}
-	return db.QueryContext(ctx, query, args...)
+	info, hooks, runner := newQueryInfo("QueryContext", s, query, args, db)
+	ctxRunner, ok := runner.(QueryerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	return hookedQueryContext(ctx, ctxRunner, hooks, info)
 }
 
 // QueryRowContextWith QueryRowContexts the SQL returned by s with db.
 func QueryRowContextWith(ctx context.Context, db QueryRowerContext, s Sqlizer) RowScanner {
-	query, args, err := s.ToSql()
-	return &Row{RowScanner: db.QueryRowContext(ctx, query, args...), err: err}
+	query, args, err := toSqlContext(ctx, s)
+	if err != nil {
+		return &Row{err: err}
+	}
+	info, hooks, runner := newQueryInfo("QueryRowContext", s, query, args, db)
+	queryRower, ok := runner.(QueryRowerContext)
+	if !ok {
+		return &Row{err: NoContextSupport}
+	}
+	return &Row{RowScanner: hookedQueryRowContext(ctx, queryRower, hooks, info)}
 }
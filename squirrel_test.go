@@ -200,3 +200,28 @@ func TestDebugSqlizerErrors(t *testing.T) {
 	errorMsg = DebugSqlizer(Lt{"x": nil}) // Cannot use nil values with Lt
 	assert.True(t, strings.HasPrefix(errorMsg, "[ToSql error: "))
 }
+
+func TestDebugSqlizerSkipsLiterals(t *testing.T) {
+	sqlizer := Expr("x = ? AND y = '?' -- ?", 1)
+	assert.Equal(t, "x = '1' AND y = '?' -- ?", DebugSqlizer(sqlizer))
+}
+
+func TestDebugSqlizerPlaceholderFormats(t *testing.T) {
+	b := Select("*").From("t").Where("a = ? AND b::text = '$1'", 1).Where(Eq{"c": "x"})
+
+	expected := "SELECT * FROM t WHERE a = '1' AND b::text = '$1' AND c = 'x'"
+	assert.Equal(t, expected, DebugSqlizer(b.PlaceholderFormat(Dollar)))
+	assert.Equal(t, expected, DebugSqlizer(b.PlaceholderFormat(Colon)))
+	assert.Equal(t, expected, DebugSqlizer(b.PlaceholderFormat(AtP)))
+}
+
+func TestDebugSqlizerNamed(t *testing.T) {
+	b := Select("*").From("t").
+		Where("kind = ?", "a").
+		Where("owner = :owner OR creator = :owner", Named{"owner": 2})
+
+	expected := "SELECT * FROM t WHERE kind = 'a' AND owner = '2' OR creator = '2'"
+	assert.Equal(t, expected, DebugSqlizer(b.Dialect(SQLServer)))
+	assert.Equal(t, expected, DebugSqlizer(b.Dialect(Oracle)))
+	assert.Equal(t, expected, DebugSqlizer(b.Dialect(Postgres)))
+}
@@ -31,11 +31,45 @@ func (b StatementBuilderType) Delete(from string) DeleteBuilder {
 	return DeleteBuilder(b).From(from)
 }
 
+// Merge returns a MergeBuilder for this StatementBuilderType.
+func (b StatementBuilderType) Merge(into string) MergeBuilder {
+	return MergeBuilder(b).Into(into)
+}
+
+// Union returns a CompoundSelectBuilder combining selects with UNION.
+func (b StatementBuilderType) Union(selects ...SelectBuilder) CompoundSelectBuilder {
+	return CompoundSelectBuilder(b).Union(selects...)
+}
+
+// UnionAll returns a CompoundSelectBuilder combining selects with UNION ALL.
+func (b StatementBuilderType) UnionAll(selects ...SelectBuilder) CompoundSelectBuilder {
+	return CompoundSelectBuilder(b).UnionAll(selects...)
+}
+
+// Intersect returns a CompoundSelectBuilder combining selects with INTERSECT.
+func (b StatementBuilderType) Intersect(selects ...SelectBuilder) CompoundSelectBuilder {
+	return CompoundSelectBuilder(b).Intersect(selects...)
+}
+
+// Except returns a CompoundSelectBuilder combining selects with EXCEPT.
+func (b StatementBuilderType) Except(selects ...SelectBuilder) CompoundSelectBuilder {
+	return CompoundSelectBuilder(b).Except(selects...)
+}
+
 // PlaceholderFormat sets the PlaceholderFormat field for any child builders.
 func (b StatementBuilderType) PlaceholderFormat(f PlaceholderFormat) StatementBuilderType {
 	return builder.Set(b, "PlaceholderFormat", f).(StatementBuilderType)
 }
 
+// Dialect sets the Dialect field, along with the PlaceholderFormat of the
+// Dialect, for any child builders.
+//
+// Ex:
+//     sq := StatementBuilder.Dialect(Postgres)
+func (b StatementBuilderType) Dialect(d Dialect) StatementBuilderType {
+	return setDialect(b, d).(StatementBuilderType)
+}
+
 // RunWith sets the RunWith field for any child builders.
 func (b StatementBuilderType) RunWith(runner BaseRunner) StatementBuilderType {
 	return setRunWith(b, runner).(StatementBuilderType)
@@ -87,6 +121,38 @@ func Delete(from string) DeleteBuilder {
 	return StatementBuilder.Delete(from)
 }
 
+// Merge returns a new MergeBuilder with the given target table name.
+//
+// See MergeBuilder.Into.
+func Merge(into string) MergeBuilder {
+	return StatementBuilder.Merge(into)
+}
+
+// Union returns a new CompoundSelectBuilder combining selects with UNION.
+//
+// Ex:
+//     Union(Select("id").From("users"), Select("id").From("admins")).OrderBy("id")
+func Union(selects ...SelectBuilder) CompoundSelectBuilder {
+	return StatementBuilder.Union(selects...)
+}
+
+// UnionAll returns a new CompoundSelectBuilder combining selects with
+// UNION ALL.
+func UnionAll(selects ...SelectBuilder) CompoundSelectBuilder {
+	return StatementBuilder.UnionAll(selects...)
+}
+
+// Intersect returns a new CompoundSelectBuilder combining selects with
+// INTERSECT.
+func Intersect(selects ...SelectBuilder) CompoundSelectBuilder {
+	return StatementBuilder.Intersect(selects...)
+}
+
+// Except returns a new CompoundSelectBuilder combining selects with EXCEPT.
+func Except(selects ...SelectBuilder) CompoundSelectBuilder {
+	return StatementBuilder.Except(selects...)
+}
+
 // Case returns a new CaseBuilder
 // "what" represents case value
 func Case(what ...interface{}) CaseBuilder {
@@ -1,9 +1,12 @@
 package squirrel
 
 import (
+	"container/list"
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // Prepareer is the interface that wraps the Prepare method.
@@ -21,81 +24,270 @@ type DBProxy interface {
 	Preparer
 }
 
-// NOTE: NewStmtCache is defined in stmtcacher_ctx.go (Go >= 1.8) or stmtcacher_noctx.go (Go < 1.8).
+// NOTE: NewStmtCache and NewStmtCacheWithOptions are defined in stmtcacher_ctx.go.
+
+// StmtCacheOptions configures a StmtCache, see NewStmtCacheWithOptions.
+type StmtCacheOptions struct {
+	// MaxSize is the maximum number of cached statements. When it is
+	// exceeded, the least recently used statement is evicted. Zero means no
+	// limit.
+	MaxSize int
+
+	// TTL is how long a statement is cached after it is prepared. Expired
+	// statements are evicted when they are next used. Zero means forever.
+	TTL time.Duration
+}
+
+// StmtCacheStats are the statistics of a StmtCache, see StmtCache.Stats.
+type StmtCacheStats struct {
+	// Size is the number of cached statements.
+	Size int
+
+	// Hits is the number of queries that used a cached statement.
+	Hits uint64
+
+	// Misses is the number of queries that were not cached. Concurrent misses
+	// of a query share one Prepare.
+	Misses uint64
+
+	// Evictions is the number of statements evicted because the cache was
+	// full or they expired.
+	Evictions uint64
+
+	// PrepareErrors is the number of failed Prepares.
+	PrepareErrors uint64
+}
 
 // StmtCache wraps and delegates down to a Preparer type
 //
 // It also automatically prepares all statements sent to the underlying Preparer calls
 // for Exec, Query and QueryRow and caches the returns *sql.Stmt using the provided
 // query as the key. So that it can be automatically re-used.
+//
+// Statements are prepared without holding a lock on the cache, and evicted
+// statements are closed once the Exec, Query and QueryRow calls using them
+// return.
 type StmtCache struct {
 	prep  Preparer
-	cache map[string]*sql.Stmt
+	opts  StmtCacheOptions
+	cache map[string]*list.Element
+	lru   *list.List
+	calls map[string]*prepareCall
+	stats StmtCacheStats
 	mu    sync.Mutex
 }
 
-// Prepare delegates down to the underlying Preparer and caches the result
-// using the provided query as a key
-func (sc *StmtCache) Prepare(query string) (*sql.Stmt, error) {
+// stmtCacheEntry is a cached statement, the Value of the elements of lru.
+type stmtCacheEntry struct {
+	query    string
+	stmt     *sql.Stmt
+	prepared time.Time
+	refs     int
+	evicted  bool
+}
+
+// prepareCall is a Prepare in progress, which concurrent misses of the same
+// query wait for.
+type prepareCall struct {
+	done    chan struct{}
+	entry   *stmtCacheEntry
+	err     error
+	waiters int
+}
+
+func newStmtCache(prep Preparer, opts StmtCacheOptions) *StmtCache {
+	return &StmtCache{
+		prep:  prep,
+		opts:  opts,
+		cache: make(map[string]*list.Element),
+		lru:   list.New(),
+		calls: make(map[string]*prepareCall),
+	}
+}
+
+// acquire returns the cache entry of query, preparing it with prepare if it
+// is not cached. The entry must be released with release.
+//
+// Concurrent misses of the same query share a single prepare, which is not
+// tied to any of them: each caller stops waiting when its ctx is done.
+func (sc *StmtCache) acquire(ctx context.Context, query string, prepare func() (*sql.Stmt, error)) (*stmtCacheEntry, error) {
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	var expired []*sql.Stmt
+	if el, ok := sc.cache[query]; ok {
+		entry := el.Value.(*stmtCacheEntry)
+		if sc.opts.TTL <= 0 || time.Since(entry.prepared) < sc.opts.TTL {
+			sc.lru.MoveToFront(el)
+			sc.stats.Hits++
+			entry.refs++
+			sc.mu.Unlock()
+			return entry, nil
+		}
+		expired = sc.evict(el, expired)
+	}
+	sc.stats.Misses++
+
+	call, ok := sc.calls[query]
+	if !ok {
+		call = &prepareCall{done: make(chan struct{})}
+		sc.calls[query] = call
+		go sc.prepare(query, call, prepare)
+	}
+	call.waiters++
+	sc.mu.Unlock()
+	closeStmts(expired)
+
+	select {
+	case <-call.done:
+		return call.entry, call.err
+	case <-ctx.Done():
+		// the entry is referenced for us, so release it when it is ready
+		go func() {
+			<-call.done
+			if call.entry != nil {
+				sc.release(call.entry)
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// prepare runs the prepare of call and caches its statement, referenced once
+// for each of its waiters.
+func (sc *StmtCache) prepare(query string, call *prepareCall, prepare func() (*sql.Stmt, error)) {
+	stmt, err := prepare()
+
+	sc.mu.Lock()
+	delete(sc.calls, query)
+	var evicted []*sql.Stmt
+	if err != nil {
+		sc.stats.PrepareErrors++
+		call.err = err
+	} else {
+		call.entry = &stmtCacheEntry{query: query, stmt: stmt, prepared: time.Now(), refs: call.waiters}
+		sc.cache[query] = sc.lru.PushFront(call.entry)
+		for sc.opts.MaxSize > 0 && sc.lru.Len() > sc.opts.MaxSize {
+			evicted = sc.evict(sc.lru.Back(), evicted)
+		}
+	}
+	sc.mu.Unlock()
 
-	stmt, ok := sc.cache[query]
-	if ok {
-		return stmt, nil
+	close(call.done)
+	closeStmts(evicted)
+}
+
+// release releases an entry returned by acquire, closing its statement if it
+// was evicted and is no longer used.
+func (sc *StmtCache) release(entry *stmtCacheEntry) {
+	sc.mu.Lock()
+	entry.refs--
+	closeStmt := entry.evicted && entry.refs == 0
+	sc.mu.Unlock()
+
+	if closeStmt && entry.stmt != nil {
+		entry.stmt.Close()
+	}
+}
+
+// evict removes el from the cache, counting it as an eviction, and appends
+// its statement to stmts if it can be closed now. sc.mu must be held.
+func (sc *StmtCache) evict(el *list.Element, stmts []*sql.Stmt) []*sql.Stmt {
+	sc.stats.Evictions++
+	return sc.remove(el, stmts)
+}
+
+// remove removes el from the cache and appends its statement to stmts if it
+// can be closed now. sc.mu must be held.
+func (sc *StmtCache) remove(el *list.Element, stmts []*sql.Stmt) []*sql.Stmt {
+	entry := sc.lru.Remove(el).(*stmtCacheEntry)
+	delete(sc.cache, entry.query)
+	entry.evicted = true
+	if entry.refs == 0 && entry.stmt != nil {
+		stmts = append(stmts, entry.stmt)
 	}
-	stmt, err := sc.prep.Prepare(query)
-	if err == nil {
-		sc.cache[query] = stmt
+	return stmts
+}
+
+func closeStmts(stmts []*sql.Stmt) (err error) {
+	for _, stmt := range stmts {
+		if cerr := stmt.Close(); cerr != nil {
+			err = cerr
+		}
 	}
-	return stmt, err
+	return
+}
+
+// Prepare delegates down to the underlying Preparer and caches the result
+// using the provided query as a key
+//
+// The statement is closed when it is evicted from the cache, so it should not
+// be kept by a cache with a MaxSize or TTL.
+func (sc *StmtCache) Prepare(query string) (*sql.Stmt, error) {
+	entry, err := sc.acquire(context.Background(), query, func() (*sql.Stmt, error) {
+		return sc.prep.Prepare(query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sc.release(entry)
+	return entry.stmt, nil
 }
 
 // Exec delegates down to the underlying Preparer using a prepared statement
 func (sc *StmtCache) Exec(query string, args ...interface{}) (res sql.Result, err error) {
-	stmt, err := sc.Prepare(query)
+	entry, err := sc.acquire(context.Background(), query, func() (*sql.Stmt, error) {
+		return sc.prep.Prepare(query)
+	})
 	if err != nil {
 		return
 	}
-	return stmt.Exec(args...)
+	defer sc.release(entry)
+	return entry.stmt.Exec(args...)
 }
 
 // Query delegates down to the underlying Preparer using a prepared statement
 func (sc *StmtCache) Query(query string, args ...interface{}) (rows *sql.Rows, err error) {
-	stmt, err := sc.Prepare(query)
+	entry, err := sc.acquire(context.Background(), query, func() (*sql.Stmt, error) {
+		return sc.prep.Prepare(query)
+	})
 	if err != nil {
 		return
 	}
-	return stmt.Query(args...)
+	defer sc.release(entry)
+	return entry.stmt.Query(args...)
 }
 
 // QueryRow delegates down to the underlying Preparer using a prepared statement
 func (sc *StmtCache) QueryRow(query string, args ...interface{}) RowScanner {
-	stmt, err := sc.Prepare(query)
+	entry, err := sc.acquire(context.Background(), query, func() (*sql.Stmt, error) {
+		return sc.prep.Prepare(query)
+	})
 	if err != nil {
 		return &Row{err: err}
 	}
-	return stmt.QueryRow(args...)
+	defer sc.release(entry)
+	return entry.stmt.QueryRow(args...)
 }
 
-// Clear removes and closes all the currently cached prepared statements
-func (sc *StmtCache) Clear() (err error) {
+// Stats returns the statistics of the cache.
+func (sc *StmtCache) Stats() StmtCacheStats {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	for key, stmt := range sc.cache {
-		delete(sc.cache, key)
-
-		if stmt == nil {
-			continue
-		}
+	stats := sc.stats
+	stats.Size = sc.lru.Len()
+	return stats
+}
 
-		if cerr := stmt.Close(); cerr != nil {
-			err = cerr
-		}
+// Clear removes and closes all the currently cached prepared statements
+func (sc *StmtCache) Clear() (err error) {
+	sc.mu.Lock()
+	var stmts []*sql.Stmt
+	for sc.lru.Len() > 0 {
+		stmts = sc.remove(sc.lru.Front(), stmts)
 	}
+	sc.mu.Unlock()
 
-	if err != nil {
+	if err = closeStmts(stmts); err != nil {
 		return fmt.Errorf("one or more Stmt.Close failed; last error: %v", err)
 	}
 
@@ -1,5 +1,3 @@
-// +build go1.8
-
 package squirrel
 
 import (
@@ -28,7 +26,16 @@ type DBProxyContext interface {
 //
 // Stmts are cached based on the string value of their queries.
 func NewStmtCache(prep PreparerContext) *StmtCache {
-	return &StmtCache{prep: prep, cache: make(map[string]*sql.Stmt)}
+	return newStmtCache(prep, StmtCacheOptions{})
+}
+
+// NewStmtCacheWithOptions returns a *StmtCache like NewStmtCache, bounded by
+// opts.
+//
+// Ex:
+//     sc := NewStmtCacheWithOptions(db, StmtCacheOptions{MaxSize: 500, TTL: time.Hour})
+func NewStmtCacheWithOptions(prep PreparerContext, opts StmtCacheOptions) *StmtCache {
+	return newStmtCache(prep, opts)
 }
 
 // NewStmtCacher is deprecated
@@ -40,47 +47,56 @@ func NewStmtCacher(prep PreparerContext) DBProxyContext {
 
 // PrepareContext delegates down to the underlying PreparerContext and caches the result
 // using the provided query as a key
+//
+// The statement is closed when it is evicted from the cache, so it should not
+// be kept by a cache with a MaxSize or TTL.
 func (sc *StmtCache) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	entry, err := sc.acquireContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	sc.release(entry)
+	return entry.stmt, nil
+}
+
+func (sc *StmtCache) acquireContext(ctx context.Context, query string) (*stmtCacheEntry, error) {
 	ctxPrep, ok := sc.prep.(PreparerContext)
 	if !ok {
 		return nil, NoContextSupport
 	}
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
-	stmt, ok := sc.cache[query]
-	if ok {
-		return stmt, nil
-	}
-	stmt, err := ctxPrep.PrepareContext(ctx, query)
-	if err == nil {
-		sc.cache[query] = stmt
-	}
-	return stmt, err
+	return sc.acquire(ctx, query, func() (*sql.Stmt, error) {
+		// the prepare is shared with concurrent callers, so it is not
+		// canceled with ctx
+		return ctxPrep.PrepareContext(context.WithoutCancel(ctx), query)
+	})
 }
 
 // ExecContext delegates down to the underlying PreparerContext using a prepared statement
 func (sc *StmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
-	stmt, err := sc.PrepareContext(ctx, query)
+	entry, err := sc.acquireContext(ctx, query)
 	if err != nil {
 		return
 	}
-	return stmt.ExecContext(ctx, args...)
+	defer sc.release(entry)
+	return entry.stmt.ExecContext(ctx, args...)
 }
 
 // QueryContext delegates down to the underlying PreparerContext using a prepared statement
 func (sc *StmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
-	stmt, err := sc.PrepareContext(ctx, query)
+	entry, err := sc.acquireContext(ctx, query)
 	if err != nil {
 		return
 	}
-	return stmt.QueryContext(ctx, args...)
+	defer sc.release(entry)
+	return entry.stmt.QueryContext(ctx, args...)
 }
 
 // QueryRowContext delegates down to the underlying PreparerContext using a prepared statement
 func (sc *StmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
-	stmt, err := sc.PrepareContext(ctx, query)
+	entry, err := sc.acquireContext(ctx, query)
 	if err != nil {
 		return &Row{err: err}
 	}
-	return stmt.QueryRowContext(ctx, args...)
+	defer sc.release(entry)
+	return entry.stmt.QueryRowContext(ctx, args...)
 }
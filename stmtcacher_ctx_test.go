@@ -1,9 +1,13 @@
-// +build go1.8
-
 package squirrel
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,3 +23,123 @@ func TestStmtCacherPrepareContext(t *testing.T) {
 	sc.PrepareContext(ctx, query)
 	assert.Equal(t, 1, db.PrepareCount, "expected 1 Prepare, got %d", db.PrepareCount)
 }
+
+// slowPreparer counts the Prepares of its DB, which wait for release.
+type slowPreparer struct {
+	*sql.DB
+	release  chan struct{}
+	prepares int32
+	err      error
+}
+
+func (p *slowPreparer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	atomic.AddInt32(&p.prepares, 1)
+	<-p.release
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.DB.PrepareContext(ctx, query)
+}
+
+func TestStmtCacheSingleflight(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	prep := &slowPreparer{DB: db, release: make(chan struct{})}
+	sc := NewStmtCache(prep)
+
+	const n = 10
+	var wg sync.WaitGroup
+	stmts := make([]*sql.Stmt, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stmts[i], _ = sc.PrepareContext(ctx, "SELECT 1")
+		}(i)
+	}
+	for sc.Stats().Misses < n {
+		time.Sleep(time.Millisecond)
+	}
+
+	// other queries are not blocked by the prepare
+	sc.Prepare("SELECT 2")
+
+	close(prep.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), prep.prepares)
+	for _, stmt := range stmts {
+		assert.NotNil(t, stmt)
+		assert.Equal(t, stmts[0], stmt)
+	}
+
+	prep.err = errors.New("prepare failed")
+	_, err := sc.PrepareContext(ctx, "SELECT 3")
+	assert.Equal(t, prep.err, err)
+	assert.Equal(t, uint64(1), sc.Stats().PrepareErrors)
+}
+
+func TestStmtCacheSingleflightCancel(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	prep := &slowPreparer{DB: db, release: make(chan struct{})}
+	sc := NewStmtCache(prep)
+
+	// the first caller starts the prepare and cancels while the second waits
+	firstCtx, cancel := context.WithCancel(ctx)
+	errs := make(chan error, 2)
+	go func() {
+		_, err := sc.PrepareContext(firstCtx, "SELECT 1")
+		errs <- err
+	}()
+	for sc.Stats().Misses < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		_, err := sc.PrepareContext(ctx, "SELECT 1")
+		errs <- err
+	}()
+	for sc.Stats().Misses < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("PrepareContext did not return when its context was canceled")
+	}
+
+	close(prep.release)
+	assert.NoError(t, <-errs)
+	assert.Equal(t, int32(1), prep.prepares)
+	assert.Equal(t, StmtCacheStats{Size: 1, Misses: 2}, sc.Stats())
+}
+
+func TestStmtCacheEvictionCloses(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	scanResults["SELECT 1"] = &scanResult{columns: []string{"a"}}
+	sc := NewStmtCacheWithOptions(db, StmtCacheOptions{MaxSize: 1})
+
+	stmt, err := sc.PrepareContext(ctx, "SELECT 1")
+	assert.NoError(t, err)
+
+	// statements in use are closed when they are released
+	entry, err := sc.acquireContext(ctx, "SELECT 1")
+	assert.NoError(t, err)
+	sc.PrepareContext(ctx, "SELECT 2")
+
+	rows, err := stmt.Query()
+	assert.NoError(t, err)
+	rows.Close()
+
+	sc.release(entry)
+	_, err = stmt.Query()
+	assert.EqualError(t, err, "sql: statement is closed")
+
+	assert.Equal(t, StmtCacheStats{Size: 1, Hits: 1, Misses: 2, Evictions: 1}, sc.Stats())
+}
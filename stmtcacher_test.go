@@ -2,6 +2,7 @@ package squirrel
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,3 +25,45 @@ func TestStmtCachePrepare(t *testing.T) {
 	sc.Prepare(query)
 	assert.Equal(t, 2, db.PrepareCount, "expected 2 Prepare, got %d", db.PrepareCount)
 }
+
+func TestStmtCacheStats(t *testing.T) {
+	db := &DBStub{}
+	sc := NewStmtCache(db)
+
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 2")
+	assert.Equal(t, StmtCacheStats{Size: 2, Hits: 1, Misses: 2}, sc.Stats())
+
+	assert.Nil(t, sc.Clear())
+	assert.Equal(t, 0, sc.Stats().Size)
+}
+
+func TestStmtCacheLRU(t *testing.T) {
+	db := &DBStub{}
+	sc := NewStmtCacheWithOptions(db, StmtCacheOptions{MaxSize: 2})
+
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 2")
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 3") // evicts SELECT 2
+	assert.Equal(t, 3, db.PrepareCount)
+
+	sc.Prepare("SELECT 1")
+	assert.Equal(t, 3, db.PrepareCount)
+	sc.Prepare("SELECT 2")
+	assert.Equal(t, 4, db.PrepareCount)
+
+	assert.Equal(t, StmtCacheStats{Size: 2, Hits: 2, Misses: 4, Evictions: 2}, sc.Stats())
+}
+
+func TestStmtCacheTTL(t *testing.T) {
+	db := &DBStub{}
+	sc := NewStmtCacheWithOptions(db, StmtCacheOptions{TTL: time.Millisecond})
+
+	sc.Prepare("SELECT 1")
+	time.Sleep(2 * time.Millisecond)
+	sc.Prepare("SELECT 1")
+	assert.Equal(t, 2, db.PrepareCount)
+	assert.Equal(t, StmtCacheStats{Size: 1, Misses: 2, Evictions: 1}, sc.Stats())
+}
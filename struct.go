@@ -0,0 +1,195 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structField is a column of a struct with db tags.
+type structField struct {
+	column    string
+	index     []int
+	omitEmpty bool
+	readonly  bool
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// structFields returns the columns of the struct type t, in field order.
+//
+// Fields are mapped by their db tag, e.g. `db:"name"`, and fields without a
+// tag are ignored. The tag options omitempty and readonly skip the column if
+// the value is empty or always. Untagged embedded structs are flattened.
+//
+// Like encoding/json, a column of several fields is mapped to the shallowest
+// one, and ignored if there are several at that depth.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	fields := dominantFields(appendStructFields(nil, t, nil))
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+func appendStructFields(fields []structField, t reflect.Type, index []int) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if !tagged {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if f.Anonymous && ft.Kind() == reflect.Struct {
+				fields = appendStructFields(fields, ft, fieldIndex)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		field := structField{column: opts[0], index: fieldIndex}
+		if field.column == "" {
+			field.column = f.Name
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "readonly":
+				field.readonly = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// dominantFields removes the fields hidden by shallower fields with the same
+// column, and the columns of several fields at the same depth.
+func dominantFields(fields []structField) []structField {
+	depth := map[string]int{}
+	count := map[string]int{}
+	for _, f := range fields {
+		d, ok := depth[f.column]
+		switch {
+		case !ok || len(f.index) < d:
+			depth[f.column] = len(f.index)
+			count[f.column] = 1
+		case len(f.index) == d:
+			count[f.column]++
+		}
+	}
+
+	dominant := fields[:0]
+	for _, f := range fields {
+		if len(f.index) == depth[f.column] && count[f.column] == 1 {
+			dominant = append(dominant, f)
+		}
+	}
+	return dominant
+}
+
+// structValue returns the struct value of v, which must be a struct or a
+// non-nil pointer to one.
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a struct or pointer to struct, not %T", v)
+	}
+	return rv, nil
+}
+
+// value returns the value of the field in struct value v, or nil if the field
+// is in a nil embedded struct.
+func (f structField) value(v reflect.Value) interface{} {
+	for _, i := range f.index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v.Interface()
+}
+
+// isEmptyValue reports whether v is a nil or zero value, or a driver.Valuer
+// with a nil value like an invalid sql.NullString.
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return true
+		}
+		value, err := valuer.Value()
+		return err == nil && value == nil
+	}
+	return reflect.ValueOf(v).IsZero()
+}
+
+// StructOption changes which fields of a struct are set by
+// UpdateBuilder.SetStruct.
+type StructOption func(*structOptions)
+
+type structOptions struct {
+	only      map[string]bool
+	omit      map[string]bool
+	omitEmpty bool
+}
+
+// OnlyColumns sets only the given columns, even if they are empty and tagged
+// omitempty.
+func OnlyColumns(columns ...string) StructOption {
+	return func(o *structOptions) {
+		o.only = columnSet(o.only, columns)
+	}
+}
+
+// OmitColumns skips the given columns, e.g. the primary key.
+func OmitColumns(columns ...string) StructOption {
+	return func(o *structOptions) {
+		o.omit = columnSet(o.omit, columns)
+	}
+}
+
+// OmitEmpty skips the columns with empty values, as if all of them were
+// tagged omitempty.
+func OmitEmpty() StructOption {
+	return func(o *structOptions) {
+		o.omitEmpty = true
+	}
+}
+
+func columnSet(set map[string]bool, columns []string) map[string]bool {
+	if set == nil {
+		set = make(map[string]bool, len(columns))
+	}
+	for _, column := range columns {
+		set[column] = true
+	}
+	return set
+}
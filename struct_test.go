@@ -0,0 +1,145 @@
+package squirrel
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type timestamps struct {
+	CreatedAt string `db:"created_at,readonly"`
+	UpdatedAt string `db:"updated_at,omitempty"`
+}
+
+type audit struct {
+	By string `db:"changed_by"`
+}
+
+type structUser struct {
+	ID   int64          `db:"id,readonly"`
+	Name string         `db:"name"`
+	Nick sql.NullString `db:"nick,omitempty"`
+	Age  *int           `db:"age,omitempty"`
+	timestamps
+	*audit
+	Version int    `db:"-"`
+	Note    string // untagged
+	secret  string `db:"secret"`
+}
+
+func TestStructFields(t *testing.T) {
+	var columns []string
+	for _, f := range structFields(reflect.TypeOf(structUser{})) {
+		columns = append(columns, f.column)
+	}
+	assert.Equal(t, []string{"id", "name", "nick", "age", "created_at", "updated_at", "changed_by"}, columns)
+}
+
+func TestInsertBuilderSetStruct(t *testing.T) {
+	age := 3
+	u := &structUser{ID: 1, Name: "moe", Age: &age, audit: &audit{By: "larry"}, secret: "x"}
+
+	sql, args, err := Insert("users").SetStruct(u).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name,age,changed_by) VALUES (?,?,?)", sql)
+	assert.Equal(t, []interface{}{"moe", &age, "larry"}, args)
+
+	u.Nick.String, u.Nick.Valid = "m", true
+	u.audit = nil
+	sql, args, err = Insert("users").SetStruct(u).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name,nick,age,changed_by) VALUES (?,?,?,?)", sql)
+	assert.Equal(t, []interface{}{"moe", u.Nick, &age, nil}, args)
+
+	_, _, err = Insert("users").SetStruct(1).ToSql()
+	assert.EqualError(t, err, "SetStruct: expected a struct or pointer to struct, not int")
+}
+
+type shadowBase struct {
+	ID    int64  `db:"id"`
+	Owner string `db:"owner"`
+}
+
+type shadowOther struct {
+	Owner string `db:"owner"`
+}
+
+type shadowUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	shadowBase
+	shadowOther
+}
+
+func TestStructFieldsShadowing(t *testing.T) {
+	u := shadowUser{shadowBase: shadowBase{ID: 1, Owner: "a"}, shadowOther: shadowOther{Owner: "b"}, ID: 2, Name: "moe"}
+
+	sql, args, err := Insert("t").SetStruct(u).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (id,name) VALUES (?,?)", sql)
+	assert.Equal(t, []interface{}{int64(2), "moe"}, args)
+}
+
+func TestSetStructKeepsErr(t *testing.T) {
+	_, _, err := Insert("t").SetStruct(1).SetStruct(shadowOther{Owner: "a"}).ToSql()
+	assert.EqualError(t, err, "SetStruct: expected a struct or pointer to struct, not int")
+
+	_, _, err = Insert("t").SetStruct(1).ValuesStructs([]shadowOther{{}}).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Insert("t").ValuesStructs(1).SetStruct(shadowOther{}).ToSql()
+	assert.Error(t, err)
+
+	db := &DBStub{}
+	_, err = Update("t").SetStruct(1).SetStruct(shadowOther{Owner: "a"}).RunWith(db).Exec()
+	assert.Error(t, err)
+	assert.Empty(t, db.LastExecSql)
+}
+
+func TestInsertBuilderValuesStructs(t *testing.T) {
+	users := []structUser{
+		{Name: "moe", audit: &audit{By: "a"}},
+		{Name: "larry", timestamps: timestamps{UpdatedAt: "now"}},
+	}
+
+	sqlStr, args, err := Insert("users").ValuesStructs(users).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name,nick,age,updated_at,changed_by) VALUES (?,?,?,?,?),(?,?,?,?,?)", sqlStr)
+	assert.Equal(t, []interface{}{
+		"moe", sql.NullString{}, (*int)(nil), "", "a",
+		"larry", sql.NullString{}, (*int)(nil), "now", nil,
+	}, args)
+
+	_, _, err = Insert("users").ValuesStructs([]interface{}{structUser{}, audit{}}).ToSql()
+	assert.EqualError(t, err, "ValuesStructs: mixed struct types squirrel.structUser and squirrel.audit")
+
+	_, _, err = Insert("users").ValuesStructs(structUser{}).ToSql()
+	assert.EqualError(t, err, "ValuesStructs: expected a slice of structs, not squirrel.structUser")
+}
+
+func TestUpdateBuilderSetStruct(t *testing.T) {
+	u := structUser{ID: 1, Name: "moe", audit: &audit{By: "a"}}
+
+	sql, args, err := Update("users").SetStruct(u).Where(Eq{"id": u.ID}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = ?, changed_by = ? WHERE id = ?", sql)
+	assert.Equal(t, []interface{}{"moe", "a", int64(1)}, args)
+
+	sql, _, err = Update("users").SetStruct(u, OmitColumns("changed_by")).Where(Eq{"id": u.ID}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = ?", sql)
+
+	sql, _, err = Update("users").SetStruct(u, OnlyColumns("nick", "age")).Where(Eq{"id": u.ID}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET nick = ?, age = ? WHERE id = ?", sql)
+
+	u.Name = ""
+	sql, _, err = Update("users").SetStruct(&u, OmitEmpty()).Where(Eq{"id": u.ID}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET changed_by = ? WHERE id = ?", sql)
+
+	_, _, err = Update("users").SetStruct(nil).ToSql()
+	assert.EqualError(t, err, "SetStruct: expected a struct or pointer to struct, not <nil>")
+}
@@ -0,0 +1,219 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+type inExpr struct {
+	column string
+	values interface{}
+	not    bool
+}
+
+// In builds a "column IN (...)" condition. values is a subquery, e.g. a
+// SelectBuilder, or a slice of values. An empty slice is always false.
+//
+// Array values like pq.Array(ids) are bound as a single arg, so they only
+// work with Any and All: In returns an error for driver.Valuers that are
+// slices or arrays, e.g. pq.Int64Array.
+//
+// Ex:
+//     In("owner_id", Select("id").From("users").Where("active"))
+//     == "owner_id IN (SELECT id FROM users WHERE active)"
+func In(column string, values interface{}) Sqlizer {
+	return inExpr{column: column, values: values}
+}
+
+// NotIn builds a "column NOT IN (...)" condition, see In. An empty slice is
+// always true.
+func NotIn(column string, values interface{}) Sqlizer {
+	return inExpr{column: column, values: values, not: true}
+}
+
+func (e inExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e inExpr) RenderSql(w *SqlWriter) error {
+	opr, emptyExpr := "IN", sqlFalse
+	if e.not {
+		opr, emptyExpr = "NOT IN", sqlTrue
+	}
+
+	if s, ok := e.values.(Sqlizer); ok {
+		fmt.Fprintf(w, "%s %s ", e.column, opr)
+		return w.writeParenthesized(s)
+	}
+
+	if isArrayValuer(e.values) {
+		return fmt.Errorf("cannot use array value %T with %s, use Any or All", e.values, opr)
+	}
+
+	values, _, err := subqueryValues(e.values, opr)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		w.WriteString(emptyExpr)
+		w.AddArgs([]interface{}{}...)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s %s (%s)", e.column, opr, Placeholders(len(values)))
+	w.AddArgs(values...)
+	return nil
+}
+
+type existsExpr struct {
+	query Sqlizer
+	not   bool
+}
+
+// Exists builds an "EXISTS (...)" condition for the subquery query.
+//
+// Ex:
+//     Exists(Select("1").From("orders").Where("orders.user_id = users.id"))
+//     == "EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)"
+func Exists(query Sqlizer) Sqlizer {
+	return existsExpr{query: query}
+}
+
+// NotExists builds a "NOT EXISTS (...)" condition, see Exists.
+func NotExists(query Sqlizer) Sqlizer {
+	return existsExpr{query: query, not: true}
+}
+
+func (e existsExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e existsExpr) RenderSql(w *SqlWriter) error {
+	if e.not {
+		w.WriteString("NOT ")
+	}
+	w.WriteString("EXISTS ")
+	return w.writeParenthesized(e.query)
+}
+
+// quantifiedOprs are the operators of Any and All.
+var quantifiedOprs = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+type quantifiedExpr struct {
+	column     string
+	opr        string
+	quantifier string
+	values     interface{}
+}
+
+// Any builds a "column op ANY (...)" condition, where op is a comparison
+// operator like "=" or ">". values is a subquery, e.g. a SelectBuilder, a
+// slice, written as an ARRAY[...] of placeholders, or an array value like
+// pq.Array(ids), bound as a single arg. Any of an empty slice is always false.
+//
+// Dialects without FeatureArrayLiteral get "column IN (...)" for "= ANY" and
+// "column NOT IN (...)" for "<> ALL" of a slice; other operators return an
+// *UnsupportedFeatureError.
+//
+// Ex:
+//     Any("price", ">", Select("price").From("items").Where("kind = ?", 2))
+//     == "price > ANY (SELECT price FROM items WHERE kind = ?)"
+func Any(column, op string, values interface{}) Sqlizer {
+	return quantifiedExpr{column: column, opr: op, quantifier: "ANY", values: values}
+}
+
+// All builds a "column op ALL (...)" condition, see Any. All of an empty
+// slice is always true.
+func All(column, op string, values interface{}) Sqlizer {
+	return quantifiedExpr{column: column, opr: op, quantifier: "ALL", values: values}
+}
+
+func (e quantifiedExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e quantifiedExpr) RenderSql(w *SqlWriter) error {
+	if !quantifiedOprs[e.opr] {
+		return fmt.Errorf("invalid operator %q for %s", e.opr, e.quantifier)
+	}
+
+	if s, ok := e.values.(Sqlizer); ok {
+		fmt.Fprintf(w, "%s %s %s ", e.column, e.opr, e.quantifier)
+		return w.writeParenthesized(s)
+	}
+
+	values, list, err := subqueryValues(e.values, e.quantifier)
+	if err != nil {
+		return err
+	}
+	if !list {
+		fmt.Fprintf(w, "%s %s %s (", e.column, e.opr, e.quantifier)
+		w.WritePlaceholder(values[0])
+		w.WriteString(")")
+		return nil
+	}
+	if len(values) == 0 {
+		if e.quantifier == "ALL" {
+			w.WriteString(sqlTrue)
+		} else {
+			w.WriteString(sqlFalse)
+		}
+		w.AddArgs([]interface{}{}...)
+		return nil
+	}
+
+	if d := w.statementDialect(nil); !d.Supports(FeatureArrayLiteral) {
+		switch {
+		case e.quantifier == "ANY" && e.opr == "=":
+			return inExpr{column: e.column, values: values}.RenderSql(w)
+		case e.quantifier == "ALL" && (e.opr == "<>" || e.opr == "!="):
+			return inExpr{column: e.column, values: values, not: true}.RenderSql(w)
+		}
+		return requireFeature(d, FeatureArrayLiteral)
+	}
+
+	fmt.Fprintf(w, "%s %s %s (ARRAY[%s])", e.column, e.opr, e.quantifier, Placeholders(len(values)))
+	w.AddArgs(values...)
+	return nil
+}
+
+// subqueryValues returns the elements of the slice values, or else values
+// itself, for the operator opr, and whether values is a slice.
+func subqueryValues(values interface{}, opr string) ([]interface{}, bool, error) {
+	val := values
+	if v, ok := val.(driver.Valuer); ok {
+		var err error
+		if val, err = v.Value(); err != nil {
+			return nil, false, err
+		}
+	}
+	if val == nil {
+		return nil, false, fmt.Errorf("cannot use null with %s", opr)
+	}
+
+	if !isListType(val) {
+		return []interface{}{values}, false, nil
+	}
+	valVal := reflect.ValueOf(val)
+	list := make([]interface{}, valVal.Len())
+	for i := range list {
+		list[i] = valVal.Index(i).Interface()
+	}
+	return list, true, nil
+}
+
+// isArrayValuer reports whether values is a driver.Valuer of an array, like
+// pq.Int64Array, which is bound as a single arg.
+func isArrayValuer(values interface{}) bool {
+	if _, ok := values.(driver.Valuer); !ok {
+		return false
+	}
+	t := reflect.TypeOf(values)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+}
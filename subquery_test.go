@@ -0,0 +1,137 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInToSql(t *testing.T) {
+	sql, args, err := In("id", []int{1, 2, 3}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "id IN (?,?,?)", sql)
+	assert.Equal(t, []interface{}{1, 2, 3}, args)
+
+	sql, args, err = NotIn("owner_id", Select("id").From("users").Where("name = ?", "moe")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "owner_id NOT IN (SELECT id FROM users WHERE name = ?)", sql)
+	assert.Equal(t, []interface{}{"moe"}, args)
+
+	sql, args, err = In("id", []int{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=0)", sql)
+	assert.Equal(t, []interface{}{}, args)
+
+	sql, _, err = NotIn("id", []string{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=1)", sql)
+
+	_, _, err = In("id", nil).ToSql()
+	assert.EqualError(t, err, "cannot use null with IN")
+}
+
+func TestExistsToSql(t *testing.T) {
+	sql, args, err := Exists(Select("1").From("orders").Where("orders.user_id = users.id AND total > ?", 10)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id AND total > ?)", sql)
+	assert.Equal(t, []interface{}{10}, args)
+
+	sql, _, err = NotExists(Select("1").From("bans")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT EXISTS (SELECT 1 FROM bans)", sql)
+}
+
+type arrayValue string
+
+func (a arrayValue) Value() (driver.Value, error) {
+	return string(a), nil
+}
+
+type int64Array []int64
+
+func (a int64Array) Value() (driver.Value, error) {
+	return fmt.Sprint(a), nil
+}
+
+func TestInArrayValue(t *testing.T) {
+	_, _, err := In("id", int64Array{1, 2}).ToSql()
+	assert.EqualError(t, err, "cannot use array value squirrel.int64Array with IN, use Any or All")
+
+	_, _, err = NotIn("id", &int64Array{1, 2}).ToSql()
+	assert.EqualError(t, err, "cannot use array value *squirrel.int64Array with NOT IN, use Any or All")
+
+	sql, args, err := Any("id", "=", int64Array{1, 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "id = ANY (?)", sql)
+	assert.Equal(t, []interface{}{int64Array{1, 2}}, args)
+}
+
+func TestAnyAllToSql(t *testing.T) {
+	sql, args, err := Any("price", ">", Select("price").From("items").Where("kind = ?", 2)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "price > ANY (SELECT price FROM items WHERE kind = ?)", sql)
+	assert.Equal(t, []interface{}{2}, args)
+
+	sql, args, err = All("id", "<>", []int{1, 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "id <> ALL (ARRAY[?,?])", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	ids := arrayValue("{1,2}")
+	sql, args, err = Any("id", "=", ids).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "id = ANY (?)", sql)
+	assert.Equal(t, []interface{}{ids}, args)
+
+	sql, _, err = Any("id", "=", []int{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=0)", sql)
+
+	sql, _, err = All("id", "=", []int{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=1)", sql)
+
+	_, _, err = Any("id", "; DROP TABLE x; --", []int{1}).ToSql()
+	assert.EqualError(t, err, `invalid operator "; DROP TABLE x; --" for ANY`)
+}
+
+func TestSubqueryPredicatesDollar(t *testing.T) {
+	sql, args, err := Select("*").From("users u").
+		Where("u.active = ?", true).
+		Where(In("u.team_id", Select("id").From("teams").Where("org = ?", 7))).
+		Where(NotExists(Select("1").From("bans b").Where("b.user_id = u.id AND b.until > ?", "now"))).
+		Where(All("u.score", ">=", []int{3, 4})).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM users u WHERE u.active = $1 " +
+		"AND u.team_id IN (SELECT id FROM teams WHERE org = $2) " +
+		"AND NOT EXISTS (SELECT 1 FROM bans b WHERE b.user_id = u.id AND b.until > $3) " +
+		"AND u.score >= ALL (ARRAY[$4,$5])"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{true, 7, "now", 3, 4}, args)
+}
+
+func TestAnyAllDialects(t *testing.T) {
+	sql, args, err := Select("*").From("t").
+		Where(Any("id", "=", []int{1, 2})).
+		Where(All("kind", "<>", []string{"a"})).
+		ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE id IN (?,?) AND kind NOT IN (?)", sql)
+	assert.Equal(t, []interface{}{1, 2, "a"}, args)
+
+	sql, _, err = Select("*").From("t").Where(Any("id", "=", []int{})).ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE (1=0)", sql)
+
+	_, _, err = Select("*").From("t").Where(Any("id", ">", []int{1, 2})).ToSqlFor(SQLite)
+	assert.EqualError(t, err, "SQLite does not support ARRAY literals")
+
+	sql, _, err = Select("*").From("t").Where(Any("id", ">", Select("id").From("u"))).ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE id > ANY (SELECT id FROM u)", sql)
+}
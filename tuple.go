@@ -0,0 +1,180 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tupleInExpr struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// TupleIn builds a "(a, b) IN ((?,?),(?,?))" condition for the composite key
+// of columns. Each row has a value for each column. Dialects without
+// FeatureRowValues get "((a = ? AND b = ?) OR (a = ? AND b = ?))" instead.
+// No rows is always false.
+//
+// Ex:
+//     TupleIn([]string{"tenant_id", "id"}, [][]interface{}{{1, 10}, {1, 11}})
+func TupleIn(columns []string, rows [][]interface{}) Sqlizer {
+	return tupleInExpr{columns: columns, rows: rows}
+}
+
+func (e tupleInExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e tupleInExpr) RenderSql(w *SqlWriter) error {
+	if len(e.columns) == 0 {
+		return fmt.Errorf("tuple comparisons need at least one column")
+	}
+	for _, row := range e.rows {
+		if len(row) != len(e.columns) {
+			return fmt.Errorf("tuple of %d values for %d columns", len(row), len(e.columns))
+		}
+	}
+	if len(e.rows) == 0 {
+		w.WriteString(sqlFalse)
+		w.AddArgs([]interface{}{}...)
+		return nil
+	}
+
+	if !w.statementDialect(nil).Supports(FeatureRowValues) {
+		w.WriteString("(")
+		for i, row := range e.rows {
+			if i > 0 {
+				w.WriteString(" OR ")
+			}
+			w.WriteString("(")
+			for j, column := range e.columns {
+				if j > 0 {
+					w.WriteString(" AND ")
+				}
+				fmt.Fprintf(w, "%s = ", column)
+				if err := writeValue(w, row[j]); err != nil {
+					return err
+				}
+			}
+			w.WriteString(")")
+		}
+		w.WriteString(")")
+		return nil
+	}
+
+	fmt.Fprintf(w, "(%s) IN (", strings.Join(e.columns, ", "))
+	for i, row := range e.rows {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("(")
+		for j, val := range row {
+			if j > 0 {
+				w.WriteString(",")
+			}
+			if err := writeValue(w, val); err != nil {
+				return err
+			}
+		}
+		w.WriteString(")")
+	}
+	w.WriteString(")")
+	return nil
+}
+
+type tupleCompareExpr struct {
+	columns []string
+	values  []interface{}
+	opr     string
+}
+
+// TupleGt builds a "(a, b) > (?, ?)" condition, e.g. for keyset pagination.
+// Dialects without FeatureRowValues get the equivalent
+// "(a > ? OR (a = ? AND b > ?))" instead.
+//
+// Ex:
+//     Select("*").From("events").
+//         Where(TupleGt([]string{"created_at", "id"}, []interface{}{lastCreatedAt, lastID})).
+//         OrderBy("created_at", "id").Limit(100)
+func TupleGt(columns []string, values []interface{}) Sqlizer {
+	return tupleCompareExpr{columns: columns, values: values, opr: ">"}
+}
+
+// TupleGtOrEq builds a "(a, b) >= (?, ?)" condition, see TupleGt.
+func TupleGtOrEq(columns []string, values []interface{}) Sqlizer {
+	return tupleCompareExpr{columns: columns, values: values, opr: ">="}
+}
+
+// TupleLt builds a "(a, b) < (?, ?)" condition, see TupleGt.
+func TupleLt(columns []string, values []interface{}) Sqlizer {
+	return tupleCompareExpr{columns: columns, values: values, opr: "<"}
+}
+
+// TupleLtOrEq builds a "(a, b) <= (?, ?)" condition, see TupleGt.
+func TupleLtOrEq(columns []string, values []interface{}) Sqlizer {
+	return tupleCompareExpr{columns: columns, values: values, opr: "<="}
+}
+
+func (e tupleCompareExpr) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(e)
+}
+
+func (e tupleCompareExpr) RenderSql(w *SqlWriter) error {
+	if len(e.columns) == 0 {
+		return fmt.Errorf("tuple comparisons need at least one column")
+	}
+	if len(e.values) != len(e.columns) {
+		return fmt.Errorf("tuple of %d values for %d columns", len(e.values), len(e.columns))
+	}
+
+	if !w.statementDialect(nil).Supports(FeatureRowValues) {
+		return e.renderExpanded(w)
+	}
+
+	fmt.Fprintf(w, "(%s) %s (", strings.Join(e.columns, ", "), e.opr)
+	for i, val := range e.values {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		if err := writeValue(w, val); err != nil {
+			return err
+		}
+	}
+	w.WriteString(")")
+	return nil
+}
+
+// renderExpanded renders the comparison without row values: the tuples
+// compare on their first column that differs, so each column i adds
+// "a = ? AND ... AND column_i > ?", with the operator of e for the last one.
+func (e tupleCompareExpr) renderExpanded(w *SqlWriter) error {
+	strict := e.opr[:1]
+
+	w.WriteString("(")
+	for i, column := range e.columns {
+		if i > 0 {
+			w.WriteString(" OR (")
+		}
+		for j := 0; j < i; j++ {
+			fmt.Fprintf(w, "%s = ", e.columns[j])
+			if err := writeValue(w, e.values[j]); err != nil {
+				return err
+			}
+			w.WriteString(" AND ")
+		}
+
+		opr := strict
+		if i == len(e.columns)-1 {
+			opr = e.opr
+		}
+		fmt.Fprintf(w, "%s %s ", column, opr)
+		if err := writeValue(w, e.values[i]); err != nil {
+			return err
+		}
+		if i > 0 {
+			w.WriteString(")")
+		}
+	}
+	w.WriteString(")")
+	return nil
+}
@@ -0,0 +1,70 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTupleInToSql(t *testing.T) {
+	b := TupleIn([]string{"tenant_id", "id"}, [][]interface{}{{1, 10}, {1, 11}})
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id, id) IN ((?,?),(?,?))", sql)
+	assert.Equal(t, []interface{}{1, 10, 1, 11}, args)
+
+	sql, args, err = Select("*").From("t").Where(b).ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE ((tenant_id = @p1 AND id = @p2) OR (tenant_id = @p3 AND id = @p4))", sql)
+	assert.Equal(t, []interface{}{1, 10, 1, 11}, args)
+
+	sql, args, err = TupleIn([]string{"a", "b"}, nil).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=0)", sql)
+	assert.Equal(t, []interface{}{}, args)
+
+	_, _, err = TupleIn([]string{"a", "b"}, [][]interface{}{{1}}).ToSql()
+	assert.EqualError(t, err, "tuple of 1 values for 2 columns")
+
+	_, _, err = TupleIn(nil, [][]interface{}{{1}}).ToSql()
+	assert.EqualError(t, err, "tuple comparisons need at least one column")
+}
+
+func TestTupleCompareToSql(t *testing.T) {
+	cols := []string{"created_at", "id"}
+	vals := []interface{}{"2020-01-01", 7}
+
+	sql, args, err := Select("*").From("events").
+		Where("kind = ?", 3).
+		Where(TupleGt(cols, vals)).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events WHERE kind = $1 AND (created_at, id) > ($2, $3)", sql)
+	assert.Equal(t, []interface{}{3, "2020-01-01", 7}, args)
+
+	sql, _, err = TupleLtOrEq(cols, vals).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(created_at, id) <= (?, ?)", sql)
+
+	sql, args, err = Select("*").From("events").
+		Where(TupleGt(cols, vals)).
+		ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events WHERE (created_at > @p1 OR (created_at = @p2 AND id > @p3))", sql)
+	assert.Equal(t, []interface{}{"2020-01-01", "2020-01-01", 7}, args)
+
+	sql, args, err = Select("*").From("t").
+		Where(TupleGtOrEq([]string{"a", "b", "c"}, []interface{}{1, 2, 3})).
+		ToSqlFor(SQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE (a > @p1 OR (a = @p2 AND b > @p3) OR (a = @p4 AND b = @p5 AND c >= @p6))", sql)
+	assert.Equal(t, []interface{}{1, 1, 2, 1, 2, 3}, args)
+
+	sql, _, err = Select("*").From("t").Where(TupleLt([]string{"a"}, []interface{}{1})).ToSqlFor(Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE (a < :1)", sql)
+
+	_, _, err = TupleGt(cols, []interface{}{1}).ToSql()
+	assert.EqualError(t, err, "tuple of 1 values for 2 columns")
+}
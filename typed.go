@@ -0,0 +1,92 @@
+package squirrel
+
+import (
+	"context"
+	"iter"
+	"reflect"
+)
+
+// QueryAll runs the query of s on db with QueryContextWith and scans all rows
+// into a slice of T, mapping columns like SelectBuilder.ScanStruct.
+// T is a struct, a pointer to a struct, or a single column type.
+//
+// Ex:
+//     users, err := QueryAll[User](ctx, db, Select("*").From("users"))
+func QueryAll[T any](ctx context.Context, db QueryerContext, s Sqlizer, opts ...ScanOption) ([]T, error) {
+	rows, err := QueryContextWith(ctx, db, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []T
+	if err := scanAll(rows, &result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryOne runs the query of s on db with QueryContextWith and scans the first
+// row into a T, see QueryAll.
+// It returns sql.ErrNoRows if there are no rows.
+func QueryOne[T any](ctx context.Context, db QueryerContext, s Sqlizer, opts ...ScanOption) (T, error) {
+	var result T
+	rows, err := QueryContextWith(ctx, db, s)
+	if err != nil {
+		return result, err
+	}
+
+	if err := scanStruct(rows, &result, opts); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// QueryIter runs the query of s on db with QueryContextWith and returns an
+// iterator over its rows scanned into T, see QueryAll. The query runs when
+// the iteration starts, and the rows are closed when it ends.
+//
+// An error stops the iteration after it is yielded with the zero T.
+//
+// Ex:
+//     for user, err := range QueryIter[User](ctx, db, Select("*").From("users")) {
+//         if err != nil {
+//             return err
+//         }
+//         ...
+//     }
+func QueryIter[T any](ctx context.Context, db QueryerContext, s Sqlizer, opts ...ScanOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, err := QueryContextWith(ctx, db, s)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		m, err := newRowMapper(rows, reflect.TypeOf((*T)(nil)).Elem(), opts)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for rows.Next() {
+			var v T
+			if err := m.scan(rows, reflect.ValueOf(&v).Elem()); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+			return
+		}
+		if err := rows.Close(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package squirrel
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryAll(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	scanResults["SELECT id, name FROM typed_users"] = &scanResult{
+		columns: []string{"id", "name"},
+		values:  [][]driver.Value{{int64(1), "moe"}, {int64(2), "larry"}},
+	}
+	q := Select("id", "name").From("typed_users")
+
+	users, err := QueryAll[scanUser](ctx, db, q)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, int64(1), users[0].ID)
+		assert.Equal(t, "larry", users[1].Name)
+	}
+
+	ptrs, err := QueryAll[*scanUser](ctx, db, q)
+	assert.NoError(t, err)
+	if assert.Len(t, ptrs, 2) {
+		assert.Equal(t, "moe", ptrs[0].Name)
+	}
+
+	_, err = QueryAll[int64](ctx, db, q)
+	assert.EqualError(t, err, "cannot scan 2 columns into int64")
+}
+
+func TestQueryOne(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	scanResults["SELECT count(*) FROM typed_users"] = &scanResult{
+		columns: []string{"count"},
+		values:  [][]driver.Value{{int64(2)}},
+	}
+	n, err := QueryOne[int64](ctx, db, Select("count(*)").From("typed_users"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	scanResults["SELECT * FROM typed_users WHERE id = ?"] = &scanResult{columns: []string{"id"}}
+	u, err := QueryOne[*scanUser](ctx, db, Select("*").From("typed_users").Where("id = ?", 3))
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Nil(t, u)
+
+	_, err = QueryOne[int64](ctx, db, Select())
+	assert.Error(t, err)
+}
+
+func TestQueryIter(t *testing.T) {
+	db := openScanStub(t)
+	defer db.Close()
+
+	r := &scanResult{
+		columns: []string{"name"},
+		values:  [][]driver.Value{{"moe"}, {"larry"}, {"curly"}},
+	}
+	scanResults["SELECT name FROM typed_users"] = r
+	q := Select("name").From("typed_users")
+
+	var names []string
+	for name, err := range QueryIter[string](ctx, db, q) {
+		assert.NoError(t, err)
+		names = append(names, name)
+		if len(names) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"moe", "larry"}, names)
+	assert.True(t, r.closed)
+
+	r.err = errors.New("connection lost")
+	var errs []error
+	for _, err := range QueryIter[string](ctx, db, q) {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{nil, nil, nil, r.err}, errs)
+}
@@ -1,19 +1,21 @@
 package squirrel
 
 import (
-	"bytes"
 	"database/sql"
 	"fmt"
 	"sort"
-	"strings"
 
 	"github.com/lann/builder"
 )
 
 type updateData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
+	Hooks             []Hook
+	Comments          []map[string]string
 	Prefixes          []Sqlizer
+	CTEs              []cte
 	Table             string
 	SetClauses        []setClause
 	From              Sqlizer
@@ -21,7 +23,9 @@ type updateData struct {
 	OrderBys          []string
 	Limit             string
 	Offset            string
+	Returning         []string
 	Suffixes          []Sqlizer
+	Err               error
 }
 
 type setClause struct {
@@ -29,21 +33,53 @@ type setClause struct {
 	value  interface{}
 }
 
-func (d *updateData) Exec() (sql.Result, error) {
+// writeSetClauses writes the comma separated "column = value" assignments of
+// clauses to w.
+func writeSetClauses(w *SqlWriter, clauses []setClause) error {
+	for i, setClause := range clauses {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		w.WriteString(setClause.column)
+		w.WriteString(" = ")
+
+		if vs, ok := setClause.value.(Sqlizer); ok {
+			if err := writeOperand(w, vs); err != nil {
+				return err
+			}
+		} else {
+			w.WritePlaceholder(setClause.value)
+		}
+	}
+	return nil
+}
+
+// sortedSetClauses returns a setClause for each key/value pair in clauses,
+// sorted by column name.
+func sortedSetClauses(clauses map[string]interface{}) []setClause {
+	keys := getSortedKeys(clauses)
+	sets := make([]setClause, len(keys))
+	for i, key := range keys {
+		sets[i] = setClause{column: key, value: clauses[key]}
+	}
+	return sets
+}
+
+func (d *updateData) Exec(b Sqlizer) (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return ExecWith(d.RunWith, d)
+	return ExecWith(d.RunWith, b)
 }
 
-func (d *updateData) Query() (*sql.Rows, error) {
+func (d *updateData) Query(b Sqlizer) (*sql.Rows, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
 	}
-	return QueryWith(d.RunWith, d)
+	return QueryWith(d.RunWith, b)
 }
 
-func (d *updateData) QueryRow() RowScanner {
+func (d *updateData) QueryRow(b Sqlizer) RowScanner {
 	if d.RunWith == nil {
 		return &Row{err: RunnerNotSet}
 	}
@@ -51,97 +87,109 @@ func (d *updateData) QueryRow() RowScanner {
 	if !ok {
 		return &Row{err: RunnerNotQueryRunner}
 	}
-	return QueryRowWith(queryRower, d)
+	return QueryRowWith(queryRower, b)
+}
+
+func (d *updateData) statementKind() string {
+	return "UPDATE"
+}
+
+func (d *updateData) statementHooks() []Hook {
+	return d.Hooks
 }
 
 func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
+	return d.toSqlComment(nil)
+}
+
+func (d *updateData) toSqlComment(tags map[string]string) (string, []interface{}, error) {
+	return statementToSql(d, d.Dialect, d.PlaceholderFormat, append(d.Comments, tags)...)
+}
+
+func (d *updateData) RenderSql(w *SqlWriter) error {
+	if d.Err != nil {
+		return d.Err
+	}
 	if len(d.Table) == 0 {
-		err = fmt.Errorf("update statements must specify a table")
-		return
+		return fmt.Errorf("update statements must specify a table")
 	}
 	if len(d.SetClauses) == 0 {
-		err = fmt.Errorf("update statements must have at least one Set clause")
-		return
+		return fmt.Errorf("update statements must have at least one Set clause")
 	}
 
-	sql := &bytes.Buffer{}
+	dialect := w.statementDialect(d.Dialect)
+	top, limit, err := dmlLimitToSql(dialect, d.OrderBys, d.Limit, d.Offset)
+	if err != nil {
+		return err
+	}
 
-	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+	output := false
+	if len(d.Returning) > 0 {
+		output, err = useOutput(dialect)
 		if err != nil {
-			return
+			return err
 		}
+	}
 
-		sql.WriteString(" ")
+	if len(d.Prefixes) > 0 {
+		if err := w.writeSqlizers(d.Prefixes, " "); err != nil {
+			return err
+		}
+
+		w.WriteString(" ")
 	}
 
-	sql.WriteString("UPDATE ")
-	sql.WriteString(d.Table)
+	if err := writeWithClause(w, dialect, d.CTEs); err != nil {
+		return err
+	}
 
-	sql.WriteString(" SET ")
-	setSqls := make([]string, len(d.SetClauses))
-	for i, setClause := range d.SetClauses {
-		var valSql string
-		if vs, ok := setClause.value.(Sqlizer); ok {
-			vsql, vargs, err := vs.ToSql()
-			if err != nil {
-				return "", nil, err
-			}
-			if _, ok := vs.(SelectBuilder); ok {
-				valSql = fmt.Sprintf("(%s)", vsql)
-			} else {
-				valSql = vsql
-			}
-			args = append(args, vargs...)
-		} else {
-			valSql = "?"
-			args = append(args, setClause.value)
-		}
-		setSqls[i] = fmt.Sprintf("%s = %s", setClause.column, valSql)
+	w.WriteString("UPDATE ")
+	w.WriteString(top)
+	w.WriteString(d.Table)
+
+	w.WriteString(" SET ")
+	if err := writeSetClauses(w, d.SetClauses); err != nil {
+		return err
+	}
+
+	if len(d.Returning) > 0 && output {
+		w.WriteString(" ")
+		w.WriteString(returningClause(d.Returning, true, "INSERTED"))
 	}
-	sql.WriteString(strings.Join(setSqls, ", "))
 
 	if d.From != nil {
-		sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
-		if err != nil {
-			return
+		if err := requireFeature(dialect, FeatureUpdateFrom); err != nil {
+			return err
 		}
-	}
 
-	if len(d.WhereParts) > 0 {
-		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
-		if err != nil {
-			return
+		w.WriteString(" FROM ")
+		if err := writeTableExpr(w, dialect, d.From); err != nil {
+			return err
 		}
 	}
 
-	if len(d.OrderBys) > 0 {
-		sql.WriteString(" ORDER BY ")
-		sql.WriteString(strings.Join(d.OrderBys, ", "))
+	if len(d.WhereParts) > 0 {
+		w.WriteString(" WHERE ")
+		if err := w.writeSqlizers(d.WhereParts, " AND "); err != nil {
+			return err
+		}
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
-	}
+	w.WriteString(limit)
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	if len(d.Returning) > 0 && !output {
+		w.WriteString(" ")
+		w.WriteString(returningClause(d.Returning, false, ""))
 	}
 
 	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+		w.WriteString(" ")
+		if err := w.writeSqlizers(d.Suffixes, " "); err != nil {
+			return err
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
-	return
+	return nil
 }
 
 // Builder
@@ -161,6 +209,12 @@ func (b UpdateBuilder) PlaceholderFormat(f PlaceholderFormat) UpdateBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(UpdateBuilder)
 }
 
+// Dialect sets the SQL Dialect (e.g. Postgres or SQLServer) of the query,
+// along with its PlaceholderFormat.
+func (b UpdateBuilder) Dialect(d Dialect) UpdateBuilder {
+	return setDialect(b, d).(UpdateBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -171,17 +225,17 @@ func (b UpdateBuilder) RunWith(runner BaseRunner) UpdateBuilder {
 // Exec builds and Execs the query with the Runner set by RunWith.
 func (b UpdateBuilder) Exec() (sql.Result, error) {
 	data := builder.GetStruct(b).(updateData)
-	return data.Exec()
+	return data.Exec(b)
 }
 
 func (b UpdateBuilder) Query() (*sql.Rows, error) {
 	data := builder.GetStruct(b).(updateData)
-	return data.Query()
+	return data.Query(b)
 }
 
 func (b UpdateBuilder) QueryRow() RowScanner {
 	data := builder.GetStruct(b).(updateData)
-	return data.QueryRow()
+	return data.QueryRow(b)
 }
 
 func (b UpdateBuilder) Scan(dest ...interface{}) error {
@@ -196,6 +250,12 @@ func (b UpdateBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+// RenderSql renders the query into w, see SqlRenderer.
+func (b UpdateBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(updateData)
+	return data.RenderSql(w)
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b UpdateBuilder) MustSql() (string, []interface{}) {
@@ -206,6 +266,25 @@ func (b UpdateBuilder) MustSql() (string, []interface{}) {
 	return sql, args
 }
 
+// ToSqlFor builds the query into a SQL string and bound args for Dialect d,
+// regardless of the Dialect and PlaceholderFormat set on the builder. The
+// builder itself is not modified.
+func (b UpdateBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return b.Dialect(d).ToSql()
+}
+
+// Compile builds the query once into a CompiledQuery, which binds the values
+// of its Param args on each execution with the Runner set by RunWith.
+func (b UpdateBuilder) Compile() (*CompiledQuery, error) {
+	data := builder.GetStruct(b).(updateData)
+	return compile(&data, data.RunWith)
+}
+
+func (b UpdateBuilder) statement() Sqlizer {
+	data := builder.GetStruct(b).(updateData)
+	return &data
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b UpdateBuilder) Prefix(sql string, args ...interface{}) UpdateBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -216,6 +295,38 @@ func (b UpdateBuilder) PrefixExpr(expr Sqlizer) UpdateBuilder {
 	return builder.Append(b, "Prefixes", expr).(UpdateBuilder)
 }
 
+// With adds a common table expression to the WITH clause of the query.
+//
+// See SelectBuilder.With for more information.
+func (b UpdateBuilder) With(name string, as Sqlizer) UpdateBuilder {
+	return builder.Append(b, "CTEs", newCTE(name, as)).(UpdateBuilder)
+}
+
+// WithRecursive adds a recursive common table expression with an optional
+// column list to the query; the clause is then rendered as WITH RECURSIVE.
+func (b UpdateBuilder) WithRecursive(name string, columns []string, as Sqlizer) UpdateBuilder {
+	c := newCTE(name, as)
+	c.columns = columns
+	c.recursive = true
+	return builder.Append(b, "CTEs", c).(UpdateBuilder)
+}
+
+// WithMaterialized adds a common table expression with the MATERIALIZED
+// hint (Postgres 12+) to the query.
+func (b UpdateBuilder) WithMaterialized(name string, as Sqlizer) UpdateBuilder {
+	c := newCTE(name, as)
+	c.materialized = "MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(UpdateBuilder)
+}
+
+// WithNotMaterialized adds a common table expression with the
+// NOT MATERIALIZED hint (Postgres 12+) to the query.
+func (b UpdateBuilder) WithNotMaterialized(name string, as Sqlizer) UpdateBuilder {
+	c := newCTE(name, as)
+	c.materialized = "NOT MATERIALIZED"
+	return builder.Append(b, "CTEs", c).(UpdateBuilder)
+}
+
 // Table sets the table to be updated.
 func (b UpdateBuilder) Table(table string) UpdateBuilder {
 	return builder.Set(b, "Table", table).(UpdateBuilder)
@@ -242,16 +353,45 @@ func (b UpdateBuilder) SetMap(clauses map[string]interface{}) UpdateBuilder {
 	return b
 }
 
+// SetStruct calls .Set for the db tagged fields of struct v, in field order.
+// Fields tagged readonly, and omitempty fields with empty values, are skipped,
+// see InsertBuilder.SetStruct. opts further restrict the fields to set.
+//
+// Ex:
+//     Update("users").SetStruct(user, OmitColumns("id")).Where(Eq{"id": user.ID})
+func (b UpdateBuilder) SetStruct(v interface{}, opts ...StructOption) UpdateBuilder {
+	rv, err := structValue(v)
+	if err != nil {
+		return builder.Set(b, "Err", fmt.Errorf("SetStruct: %v", err)).(UpdateBuilder)
+	}
+
+	o := structOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for _, f := range structFields(rv.Type()) {
+		if f.readonly || o.omit[f.column] || o.only != nil && !o.only[f.column] {
+			continue
+		}
+		val := f.value(rv)
+		if (f.omitEmpty || o.omitEmpty) && !o.only[f.column] && isEmptyValue(val) {
+			continue
+		}
+		b = b.Set(f.column, val)
+	}
+	return b
+}
+
 // From adds FROM clause to the query
 // FROM is valid construct in postgresql only.
 func (b UpdateBuilder) From(from string) UpdateBuilder {
 	return builder.Set(b, "From", newPart(from)).(UpdateBuilder)
 }
 
-// FromSelect sets a subquery into the FROM clause of the query.
-func (b UpdateBuilder) FromSelect(from SelectBuilder, alias string) UpdateBuilder {
-	// Prevent misnumbered parameters in nested selects (#183).
-	from = from.PlaceholderFormat(Question)
+// FromSelect sets a subquery (e.g. a SelectBuilder or CompoundSelectBuilder)
+// into the FROM clause of the query.
+func (b UpdateBuilder) FromSelect(from Sqlizer, alias string) UpdateBuilder {
 	return builder.Set(b, "From", Alias(from, alias)).(UpdateBuilder)
 }
 
@@ -277,6 +417,14 @@ func (b UpdateBuilder) Offset(offset uint64) UpdateBuilder {
 	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(UpdateBuilder)
 }
 
+// Returning adds columns to the RETURNING clause of the query. With the
+// SQLServer Dialect it is rendered as an OUTPUT clause where
+// unqualified columns are read from INSERTED; use e.g. "DELETED.name" for the
+// values before the update.
+func (b UpdateBuilder) Returning(columns ...string) UpdateBuilder {
+	return builder.Extend(b, "Returning", columns).(UpdateBuilder)
+}
+
 // Suffix adds an expression to the end of the query
 func (b UpdateBuilder) Suffix(sql string, args ...interface{}) UpdateBuilder {
 	return b.SuffixExpr(Expr(sql, args...))
@@ -286,3 +434,8 @@ func (b UpdateBuilder) Suffix(sql string, args ...interface{}) UpdateBuilder {
 func (b UpdateBuilder) SuffixExpr(expr Sqlizer) UpdateBuilder {
 	return builder.Append(b, "Suffixes", expr).(UpdateBuilder)
 }
+
+// Comment adds a comment with tags to the query, see SelectBuilder.Comment.
+func (b UpdateBuilder) Comment(tags map[string]string) UpdateBuilder {
+	return builder.Append(b, "Comments", tags).(UpdateBuilder)
+}
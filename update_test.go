@@ -105,3 +105,29 @@ func TestUpdateBuilderFromSelect(t *testing.T) {
 			"WHERE employees.account_id = subquery.id"
 	assert.Equal(t, expectedSql, sql)
 }
+
+func TestUpdateBuilderToSqlFor(t *testing.T) {
+	b := Update("users").Set("name", "moe").Where("id = ?", 1)
+
+	sql, args, err := b.ToSqlFor(Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = :1 WHERE id = :2", sql)
+	assert.Equal(t, []interface{}{"moe", 1}, args)
+}
+
+func TestUpdateBuilderSetCompoundSelect(t *testing.T) {
+	u := Union(Select("a").From("x").Where("k = ?", 1), Select("a").From("y"))
+
+	sql, args, err := Update("t").Set("a", u).Where("id = ?", 2).PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = (SELECT a FROM x WHERE k = $1 UNION SELECT a FROM y) WHERE id = $2", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	sql, _, err = Insert("t").Values(1).OnConflict("id").DoUpdateSet("a", u).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) ON CONFLICT (id) DO UPDATE SET a = (SELECT a FROM x WHERE k = ? UNION SELECT a FROM y)", sql)
+
+	sql, _, err = Merge("t").Using("s").On("t.id = s.id").When(WhenMatched().Set("a", u)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED THEN UPDATE SET a = (SELECT a FROM x WHERE k = ? UNION SELECT a FROM y)", sql)
+}
@@ -0,0 +1,95 @@
+package squirrel
+
+import (
+	"errors"
+	"strings"
+)
+
+// Excluded refers to the value proposed for insertion of column in the
+// DO UPDATE action of an ON CONFLICT clause (Postgres, SQLite).
+//
+// Ex:
+//     Insert("t").Columns("id", "n").Values(1, 2).
+//         OnConflict("id").DoUpdateSet("n", Excluded("n"))
+func Excluded(column string) Sqlizer {
+	return Expr("EXCLUDED." + column)
+}
+
+// Values refers to the value proposed for insertion of column in the
+// ON DUPLICATE KEY UPDATE clause (MySQL).
+//
+// MySQL 8.0.20+ deprecates VALUES() in favor of a row alias, see
+// InsertBuilder.RowAlias.
+func Values(column string) Sqlizer {
+	return Expr("VALUES(" + column + ")")
+}
+
+// writeOnConflict writes the ON CONFLICT clause of d, if any, preceded by a
+// space to w.
+func (d *insertData) writeOnConflict(w *SqlWriter, dialect Dialect) error {
+	hasTarget := len(d.ConflictColumns) > 0 || len(d.ConflictConstraint) > 0
+	if !hasTarget && len(d.ConflictAction) == 0 && len(d.ConflictWhereParts) == 0 {
+		return nil
+	}
+
+	if err := requireFeature(dialect, FeatureOnConflict); err != nil {
+		return err
+	}
+
+	w.WriteString(" ON CONFLICT")
+	if len(d.ConflictConstraint) > 0 {
+		w.WriteString(" ON CONSTRAINT ")
+		w.WriteString(d.ConflictConstraint)
+	} else if len(d.ConflictColumns) > 0 {
+		w.WriteString(" (")
+		w.WriteString(strings.Join(d.ConflictColumns, ", "))
+		w.WriteString(")")
+	}
+
+	switch d.ConflictAction {
+	case "NOTHING":
+		if len(d.ConflictSetClauses) > 0 || len(d.ConflictWhereParts) > 0 {
+			return errors.New("ON CONFLICT DO NOTHING cannot be combined with DoUpdateSet or DoUpdateWhere")
+		}
+		w.WriteString(" DO NOTHING")
+	case "UPDATE":
+		if !hasTarget {
+			return errors.New("ON CONFLICT DO UPDATE requires a conflict target; use OnConflict or OnConflictOnConstraint")
+		}
+
+		w.WriteString(" DO UPDATE SET ")
+		if err := writeSetClauses(w, d.ConflictSetClauses); err != nil {
+			return err
+		}
+
+		if len(d.ConflictWhereParts) > 0 {
+			w.WriteString(" WHERE ")
+			if err := w.writeSqlizers(d.ConflictWhereParts, " AND "); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("ON CONFLICT requires an action; use DoNothing or DoUpdateSet")
+	}
+
+	return nil
+}
+
+// writeOnDuplicateKey writes the ON DUPLICATE KEY UPDATE clause of d, if any,
+// preceded by a space to w.
+func (d *insertData) writeOnDuplicateKey(w *SqlWriter, dialect Dialect) error {
+	if len(d.DuplicateKeySetClauses) == 0 {
+		return nil
+	}
+
+	if len(d.ConflictColumns) > 0 || len(d.ConflictConstraint) > 0 || len(d.ConflictAction) > 0 {
+		return errors.New("ON CONFLICT and ON DUPLICATE KEY UPDATE cannot be used together")
+	}
+
+	if err := requireFeature(dialect, FeatureOnDuplicateKey); err != nil {
+		return err
+	}
+
+	w.WriteString(" ON DUPLICATE KEY UPDATE ")
+	return writeSetClauses(w, d.DuplicateKeySetClauses)
+}
@@ -0,0 +1,145 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertBuilderOnConflictDoUpdate(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name", "visits").
+		Values(1, "moe", 1).
+		OnConflict("id").
+		DoUpdateSet("name", Excluded("name")).
+		DoUpdateSet("visits", Expr("users.visits + ?", 1)).
+		DoUpdateWhere("users.locked = ?", false).
+		Suffix("RETURNING id").
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO users (id,name,visits) VALUES ($1,$2,$3) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, visits = users.visits + $4 " +
+		"WHERE users.locked = $5 RETURNING id"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, "moe", 1, 1, false}, args)
+}
+
+func TestInsertBuilderOnConflictDoUpdateSetMap(t *testing.T) {
+	b := Insert("t").
+		SetMap(map[string]interface{}{"a": 1, "b": 2, "c": 3}).
+		OnConflict("a", "b").
+		DoUpdateSetMap(map[string]interface{}{"c": Excluded("c"), "b": 4})
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO t (a,b,c) VALUES (?,?,?) " +
+		"ON CONFLICT (a, b) DO UPDATE SET b = ?, c = EXCLUDED.c"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, args)
+}
+
+func TestInsertBuilderOnConflictDoNothing(t *testing.T) {
+	sql, _, err := Insert("t").Values(1).DoNothing().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) ON CONFLICT DO NOTHING", sql)
+
+	sql, _, err = Insert("t").Values(1).OnConflictOnConstraint("t_pkey").DoNothing().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) ON CONFLICT ON CONSTRAINT t_pkey DO NOTHING", sql)
+}
+
+func TestInsertBuilderOnConflictSelect(t *testing.T) {
+	b := Insert("t").
+		Columns("id").
+		Select(Select("id").From("s").Where("x = ?", 1)).
+		OnConflict("id").
+		DoNothing()
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (id) SELECT id FROM s WHERE x = ? ON CONFLICT (id) DO NOTHING", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestInsertBuilderOnConflictTargetReplaced(t *testing.T) {
+	sql, _, err := Insert("t").Values(1).OnConflict("id").OnConflictOnConstraint("t_pkey").DoNothing().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) ON CONFLICT ON CONSTRAINT t_pkey DO NOTHING", sql)
+
+	sql, _, err = Insert("t").Values(1).OnConflictOnConstraint("t_pkey").OnConflict("id").DoNothing().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) ON CONFLICT (id) DO NOTHING", sql)
+}
+
+func TestInsertBuilderOnConflictErr(t *testing.T) {
+	_, _, err := Insert("t").Values(1).OnConflict("id").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Insert("t").Values(1).DoUpdateSet("a", 1).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Insert("t").Values(1).DoUpdateWhere("a = 1").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Insert("t").Values(1).OnConflict("id").DoUpdateSet("a", 1).DoNothing().ToSql()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderOnDuplicateKeyUpdate(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name", "visits").
+		Values(1, "moe", 1).
+		Values(2, "larry", 1).
+		OnDuplicateKeyUpdate("name", Values("name")).
+		OnDuplicateKeyUpdate("visits", Expr("visits + ?", 1)).
+		Suffix("/* ? */", "end")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO users (id,name,visits) VALUES (?,?,?),(?,?,?) " +
+		"ON DUPLICATE KEY UPDATE name = VALUES(name), visits = visits + ? /* ? */"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, "moe", 1, 2, "larry", 1, 1, "end"}, args)
+}
+
+func TestInsertBuilderOnDuplicateKeyUpdateRowAlias(t *testing.T) {
+	b := Insert("t").
+		Columns("a", "b").
+		Values(1, 2).
+		RowAlias("new").
+		OnDuplicateKeyUpdateMap(map[string]interface{}{"b": Expr("new.b"), "a": 3})
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO t (a,b) VALUES (?,?) AS new ON DUPLICATE KEY UPDATE a = ?, b = new.b"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2, 3}, args)
+}
+
+func TestInsertBuilderOnDuplicateKeyUpdateSelect(t *testing.T) {
+	b := Insert("t").
+		Columns("a", "b").
+		Select(Select("a", "b").From("s").Where("c = ?", 1)).
+		OnDuplicateKeyUpdate("b", Values("b"))
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "INSERT INTO t (a,b) SELECT a, b FROM s WHERE c = ? ON DUPLICATE KEY UPDATE b = VALUES(b)"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestInsertBuilderOnDuplicateKeyUpdateErr(t *testing.T) {
+	_, _, err := Insert("t").Values(1).OnConflict("a").DoNothing().OnDuplicateKeyUpdate("a", 1).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Insert("t").Select(Select("a").From("s")).RowAlias("new").ToSql()
+	assert.Error(t, err)
+}
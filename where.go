@@ -11,20 +11,25 @@ func newWherePart(pred interface{}, args ...interface{}) Sqlizer {
 }
 
 func (p wherePart) ToSql() (sql string, args []interface{}, err error) {
+	return renderToSql(p)
+}
+
+func (p wherePart) RenderSql(w *SqlWriter) error {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
-	case rawSqlizer:
-		return pred.toSqlRaw()
 	case Sqlizer:
-		return pred.ToSql()
+		return w.WriteSqlizer(pred)
 	case map[string]interface{}:
-		return Eq(pred).ToSql()
+		return w.WriteSqlizer(Eq(pred))
 	case string:
-		sql = pred
-		args = p.args
+		if names, ok := namedArgs(p.args); ok {
+			return writeNamed(w, pred, names)
+		}
+		w.WriteString(pred)
+		w.AddArgs(p.args...)
 	default:
-		err = fmt.Errorf("expected string-keyed map or string, not %T", pred)
+		return fmt.Errorf("expected string-keyed map or string, not %T", pred)
 	}
-	return
+	return nil
 }
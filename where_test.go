@@ -3,26 +3,25 @@ package squirrel
 import (
 	"testing"
 
-	"bytes"
-
 	"github.com/stretchr/testify/assert"
 )
 
-func TestWherePartsAppendToSql(t *testing.T) {
+func TestWherePartsWriteSqlizers(t *testing.T) {
 	parts := []Sqlizer{
 		newWherePart("x = ?", 1),
 		newWherePart(nil),
 		newWherePart(Eq{"y": 2}),
 	}
-	sql := &bytes.Buffer{}
-	args, _ := appendToSql(parts, sql, " AND ", []interface{}{})
-	assert.Equal(t, "x = ? AND y = ?", sql.String())
-	assert.Equal(t, []interface{}{1, 2}, args)
+	w := NewSqlWriter(nil)
+	err := w.writeSqlizers(parts, " AND ")
+	assert.NoError(t, err)
+	assert.Equal(t, "x = ? AND y = ?", w.String())
+	assert.Equal(t, []interface{}{1, 2}, w.Args())
 }
 
-func TestWherePartsAppendToSqlErr(t *testing.T) {
+func TestWherePartsWriteSqlizersErr(t *testing.T) {
 	parts := []Sqlizer{newWherePart(1)}
-	_, err := appendToSql(parts, &bytes.Buffer{}, "", []interface{}{})
+	err := NewSqlWriter(nil).writeSqlizers(parts, "")
 	assert.Error(t, err)
 }
 
@@ -0,0 +1,226 @@
+package squirrel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+func init() {
+	builder.Register(WindowBuilder{}, windowData{})
+}
+
+// FrameBound is one end of a window frame, e.g. UnboundedPreceding or
+// Preceding(3).
+type FrameBound string
+
+const (
+	// UnboundedPreceding is the UNBOUNDED PRECEDING frame bound.
+	UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING"
+
+	// CurrentRow is the CURRENT ROW frame bound.
+	CurrentRow FrameBound = "CURRENT ROW"
+
+	// UnboundedFollowing is the UNBOUNDED FOLLOWING frame bound.
+	UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding returns the "<offset> PRECEDING" frame bound.
+func Preceding(offset uint64) FrameBound {
+	return FrameBound(fmt.Sprintf("%d PRECEDING", offset))
+}
+
+// Following returns the "<offset> FOLLOWING" frame bound.
+func Following(offset uint64) FrameBound {
+	return FrameBound(fmt.Sprintf("%d FOLLOWING", offset))
+}
+
+// windowData holds all the data required to build a window function call or a
+// window definition
+type windowData struct {
+	Function     Sqlizer
+	Base         string
+	PartitionBys []string
+	OrderByParts []Sqlizer
+	Frame        string
+}
+
+// ToSql implements Sqlizer
+func (d *windowData) ToSql() (sqlStr string, args []interface{}, err error) {
+	return renderToSql(d)
+}
+
+// RenderSql implements SqlRenderer
+func (d *windowData) RenderSql(w *SqlWriter) error {
+	if d.Function == nil {
+		return d.writeSpec(w)
+	}
+
+	if err := w.WriteSqlizer(d.Function); err != nil {
+		return err
+	}
+
+	w.WriteString(" OVER ")
+	if len(d.Base) > 0 && len(d.PartitionBys) == 0 && len(d.OrderByParts) == 0 && len(d.Frame) == 0 {
+		w.WriteString(d.Base)
+		return nil
+	}
+	return d.writeSpec(w)
+}
+
+// writeSpec writes the parenthesized window specification.
+func (d *windowData) writeSpec(w *SqlWriter) error {
+	w.WriteString("(")
+	sep := ""
+
+	if len(d.Base) > 0 {
+		w.WriteString(d.Base)
+		sep = " "
+	}
+
+	if len(d.PartitionBys) > 0 {
+		w.WriteString(sep + "PARTITION BY " + strings.Join(d.PartitionBys, ", "))
+		sep = " "
+	}
+
+	if len(d.OrderByParts) > 0 {
+		w.WriteString(sep + "ORDER BY ")
+		if err := w.writeSqlizers(d.OrderByParts, ", "); err != nil {
+			return err
+		}
+		sep = " "
+	}
+
+	if len(d.Frame) > 0 {
+		w.WriteString(sep + d.Frame)
+	}
+
+	w.WriteString(")")
+	return nil
+}
+
+// WindowBuilder builds SQL window function calls like
+// "ROW_NUMBER() OVER (PARTITION BY ... ORDER BY ...)" and window definitions
+// for SelectBuilder.Window.
+type WindowBuilder builder.Builder
+
+// Over returns a new WindowBuilder for a window function call, which can be
+// used as a column of a SelectBuilder.
+//
+// Ex:
+//     Over("ROW_NUMBER()").PartitionBy("dept").OrderBy("salary DESC")
+func Over(function string, args ...interface{}) WindowBuilder {
+	return builder.Set(WindowBuilder(builder.EmptyBuilder), "Function", newPart(function, args...)).(WindowBuilder)
+}
+
+// Window returns a new WindowBuilder for a window definition without a
+// function, to be named with SelectBuilder.Window.
+func Window() WindowBuilder {
+	return WindowBuilder(builder.EmptyBuilder)
+}
+
+// ToSql builds the window function call or definition into a SQL string and
+// bound args.
+func (b WindowBuilder) ToSql() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(windowData)
+	return data.ToSql()
+}
+
+// RenderSql renders the window function call or definition into w, see
+// SqlRenderer.
+func (b WindowBuilder) RenderSql(w *SqlWriter) error {
+	data := builder.GetStruct(b).(windowData)
+	return data.RenderSql(w)
+}
+
+// MustSql builds the window function call or definition into a SQL string and
+// bound args.
+// It panics if there are any errors.
+func (b WindowBuilder) MustSql() (string, []interface{}) {
+	sql, args, err := b.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// ToSqlFor builds the window function call or definition into a SQL string and
+// bound args in Dialect d.
+func (b WindowBuilder) ToSqlFor(d Dialect) (string, []interface{}, error) {
+	return statementToSql(b, d, d.PlaceholderFormat())
+}
+
+// Extends bases the window on an existing named window, see
+// SelectBuilder.Window. A window function call that only extends a named
+// window is rendered as "OVER name".
+func (b WindowBuilder) Extends(name string) WindowBuilder {
+	return builder.Set(b, "Base", name).(WindowBuilder)
+}
+
+// PartitionBy adds PARTITION BY expressions to the window.
+func (b WindowBuilder) PartitionBy(partitionBys ...string) WindowBuilder {
+	return builder.Extend(b, "PartitionBys", partitionBys).(WindowBuilder)
+}
+
+// OrderByClause adds ORDER BY clause to the window.
+func (b WindowBuilder) OrderByClause(pred interface{}, args ...interface{}) WindowBuilder {
+	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(WindowBuilder)
+}
+
+// OrderBy adds ORDER BY expressions to the window.
+func (b WindowBuilder) OrderBy(orderBys ...string) WindowBuilder {
+	for _, orderBy := range orderBys {
+		b = b.OrderByClause(orderBy)
+	}
+
+	return b
+}
+
+// Rows sets a "ROWS BETWEEN start AND end" frame clause on the window.
+// If end is empty, the frame clause is "ROWS start".
+func (b WindowBuilder) Rows(start, end FrameBound) WindowBuilder {
+	return b.frame("ROWS", start, end)
+}
+
+// Range sets a "RANGE BETWEEN start AND end" frame clause on the window.
+// If end is empty, the frame clause is "RANGE start".
+func (b WindowBuilder) Range(start, end FrameBound) WindowBuilder {
+	return b.frame("RANGE", start, end)
+}
+
+// Groups sets a "GROUPS BETWEEN start AND end" frame clause on the window.
+// If end is empty, the frame clause is "GROUPS start".
+func (b WindowBuilder) Groups(start, end FrameBound) WindowBuilder {
+	return b.frame("GROUPS", start, end)
+}
+
+func (b WindowBuilder) frame(mode string, start, end FrameBound) WindowBuilder {
+	frame := fmt.Sprintf("%s %s", mode, start)
+	if len(end) > 0 {
+		frame = fmt.Sprintf("%s BETWEEN %s AND %s", mode, start, end)
+	}
+	return builder.Set(b, "Frame", frame).(WindowBuilder)
+}
+
+// namedWindow is a single definition of a WINDOW clause.
+type namedWindow struct {
+	name string
+	spec WindowBuilder
+}
+
+func (w namedWindow) ToSql() (string, []interface{}, error) {
+	return renderToSql(w)
+}
+
+func (w namedWindow) RenderSql(sql *SqlWriter) error {
+	data := builder.GetStruct(w.spec).(windowData)
+	if data.Function != nil {
+		return errors.New("window definitions must not have a window function; use Window instead of Over")
+	}
+
+	sql.WriteString(w.name)
+	sql.WriteString(" AS ")
+	return data.writeSpec(sql)
+}
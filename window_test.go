@@ -0,0 +1,131 @@
+package squirrel
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverToSql(t *testing.T) {
+	b := Over("ROW_NUMBER()").PartitionBy("dept", "team").OrderBy("salary DESC", "id")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "ROW_NUMBER() OVER (PARTITION BY dept, team ORDER BY salary DESC, id)"
+	assert.Equal(t, expectedSql, sql)
+	assert.Empty(t, args)
+}
+
+func TestOverWithArgs(t *testing.T) {
+	b := Over("NTILE(?)", 4).OrderByClause("abs(x - ?)", 10)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "NTILE(?) OVER (ORDER BY abs(x - ?))"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{4, 10}, args)
+}
+
+func TestOverFrames(t *testing.T) {
+	sql, _, err := Over("SUM(x)").OrderBy("t").Rows(Preceding(3), CurrentRow).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SUM(x) OVER (ORDER BY t ROWS BETWEEN 3 PRECEDING AND CURRENT ROW)", sql)
+
+	sql, _, err = Over("SUM(x)").OrderBy("t").Range(UnboundedPreceding, "").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SUM(x) OVER (ORDER BY t RANGE UNBOUNDED PRECEDING)", sql)
+
+	sql, _, err = Over("SUM(x)").OrderBy("t").Groups(CurrentRow, Following(2)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SUM(x) OVER (ORDER BY t GROUPS BETWEEN CURRENT ROW AND 2 FOLLOWING)", sql)
+
+	sql, _, err = Over("COUNT(*)").Rows(UnboundedPreceding, UnboundedFollowing).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "COUNT(*) OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)", sql)
+}
+
+func TestOverEmpty(t *testing.T) {
+	sql, _, err := Over("COUNT(*)").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "COUNT(*) OVER ()", sql)
+}
+
+func TestOverExtends(t *testing.T) {
+	sql, _, err := Over("rank()").Extends("w").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "rank() OVER w", sql)
+
+	sql, _, err = Over("SUM(x)").Extends("w").Rows(Preceding(1), "").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SUM(x) OVER (w ROWS 1 PRECEDING)", sql)
+}
+
+func TestSelectBuilderWindow(t *testing.T) {
+	b := Select("id").
+		Column(Alias(Over("ROW_NUMBER()").PartitionBy("dept").OrderBy("salary DESC"), "rn")).
+		Column(Over("rank()").Extends("w")).
+		From("emp").
+		Where("active = ?", true).
+		GroupBy("dept", "id", "salary").
+		Having("count(*) > ?", 1).
+		Window("w", Window().PartitionBy("dept").OrderByClause("salary * ? DESC", 2)).
+		Window("w2", Window().Extends("w").Rows(UnboundedPreceding, CurrentRow)).
+		OrderBy("id").
+		Limit(3)
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT id, (ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC)) AS rn, " +
+		"rank() OVER w FROM emp WHERE active = $1 GROUP BY dept, id, salary HAVING count(*) > $2 " +
+		"WINDOW w AS (PARTITION BY dept ORDER BY salary * $3 DESC), " +
+		"w2 AS (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) " +
+		"ORDER BY id LIMIT 3"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{true, 1, 2}, args)
+}
+
+func TestSelectBuilderWindowErr(t *testing.T) {
+	_, _, err := Select("id").From("emp").Window("w", Over("rank()")).ToSql()
+	assert.Error(t, err)
+}
+
+func TestOverMustSql(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("TestOverMustSql should have panicked!")
+		}
+	}()
+	Over("x", 1).OrderByClause(1).MustSql()
+}
+
+func TestSelectBuilderWindowNamedArgs(t *testing.T) {
+	b := Select("id").
+		Column(Over("rank()").OrderByClause("abs(score - :target)", Named{"target": 5})).
+		From("emp").
+		Where("dept = :dept AND score > :target", Named{"dept": "a", "target": 5})
+
+	sqlStr, args, err := b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT id, rank() OVER (ORDER BY abs(score - @target)) FROM emp " +
+		"WHERE dept = @dept AND score > @target"
+	assert.Equal(t, expectedSql, sqlStr)
+	assert.Equal(t, []interface{}{sql.Named("target", 5), sql.Named("dept", "a")}, args)
+}
+
+func TestOverToSqlFor(t *testing.T) {
+	b := Over("SUM(x)").OrderByClause(Case().When(IsNotDistinctFrom{"a": 1}, "0").Else("1"))
+
+	sqlStr, args, err := b.ToSqlFor(MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SUM(x) OVER (ORDER BY CASE WHEN a <=> ? THEN 0 ELSE 1 END)", sqlStr)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sqlStr, _, err = b.ToSqlFor(Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "SUM(x) OVER (ORDER BY CASE WHEN a IS NOT DISTINCT FROM $1 THEN 0 ELSE 1 END)", sqlStr)
+}